@@ -8,6 +8,7 @@ import (
 
 	"github.com/lieyanc/fire-commit/internal/cli"
 	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/debuglog"
 	"github.com/lieyanc/fire-commit/internal/updater"
 )
 
@@ -16,6 +17,16 @@ var version = "dev"
 func main() {
 	cli.SetVersion(version)
 
+	// Debug logging is enabled before anything else so the background
+	// update check is traced too.
+	debug := debugRequested(os.Args[1:])
+	if debug {
+		if err := debuglog.Enable(); err != nil {
+			fmt.Fprintf(os.Stderr, "Debug log unavailable: %v\n", err)
+			debug = false
+		}
+	}
+
 	channel := updater.ChannelLatest
 	cfg, cfgErr := config.Load()
 	if cfgErr == nil && cfg.UpdateChannel != "" {
@@ -80,6 +91,11 @@ func main() {
 		}
 	}
 
+	if debug {
+		debuglog.Close()
+		fmt.Fprintf(os.Stderr, "Debug log written to %s\n", debuglog.Path())
+	}
+
 	if err != nil {
 		os.Exit(1)
 	}
@@ -133,6 +149,22 @@ func shouldSkipAutoCheck(args []string) bool {
 	return subcmd == "update" || subcmd == "rollback" || subcmd == "tag"
 }
 
+// debugRequested reports whether --debug was passed or FIRECOMMIT_DEBUG is set.
+func debugRequested(args []string) bool {
+	if debuglog.EnabledByEnv() {
+		return true
+	}
+	for _, a := range args {
+		if a == "--" {
+			break
+		}
+		if a == "--debug" || a == "--debug=true" {
+			return true
+		}
+	}
+	return false
+}
+
 func firstSubcommand(args []string) string {
 	for _, a := range args {
 		if a == "--" {
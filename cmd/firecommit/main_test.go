@@ -136,3 +136,27 @@ func TestUpdateCacheEnabled(t *testing.T) {
 type assertErr struct{}
 
 func (assertErr) Error() string { return "test err" }
+
+func TestDebugRequested(t *testing.T) {
+	t.Setenv("FIRECOMMIT_DEBUG", "")
+
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: nil, want: false},
+		{args: []string{"--debug"}, want: true},
+		{args: []string{"models", "--debug=true"}, want: true},
+		{args: []string{"--", "--debug"}, want: false},
+	}
+	for _, tc := range tests {
+		if got := debugRequested(tc.args); got != tc.want {
+			t.Fatalf("debugRequested(%v) got %v want %v", tc.args, got, tc.want)
+		}
+	}
+
+	t.Setenv("FIRECOMMIT_DEBUG", "1")
+	if !debugRequested(nil) {
+		t.Fatalf("expected FIRECOMMIT_DEBUG=1 to enable debug logging")
+	}
+}
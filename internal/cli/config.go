@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
 	"github.com/lieyanc/fire-commit/internal/config"
@@ -81,9 +82,41 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Config file: %s\n\n", config.ConfigPath())
 	os.Stdout.Write(data)
+
+	if len(cfg.Providers) > 0 {
+		names := make([]string, 0, len(cfg.Providers))
+		for name := range cfg.Providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Println()
+		fmt.Println("API key sources:")
+		for _, name := range names {
+			fmt.Printf("  %s: %s\n", name, describeKeySource(cfg.Providers[name]))
+		}
+	}
 	return nil
 }
 
+// describeKeySource explains where a provider's API key is read from without
+// revealing the key itself.
+func describeKeySource(p config.ProviderConfig) string {
+	switch p.APIKeySource() {
+	case config.KeySourceConfig:
+		return "plaintext in config file"
+	case config.KeySourceEnv:
+		if os.Getenv(p.APIKeyEnv) == "" {
+			return fmt.Sprintf("environment variable %s (not set)", p.APIKeyEnv)
+		}
+		return fmt.Sprintf("environment variable %s", p.APIKeyEnv)
+	case config.KeySourceCommand:
+		return fmt.Sprintf("command `%s`", p.APIKeyCmd)
+	default:
+		return "not configured"
+	}
+}
+
 func runConfigSetup(cmd *cobra.Command, args []string) error {
 	_, err := setup.RunWizard()
 	return err
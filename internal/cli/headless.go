@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+	"github.com/lieyanc/fire-commit/internal/llm"
+	"github.com/lieyanc/fire-commit/internal/tui"
+)
+
+// Root flags for scripted, non-interactive runs.
+var (
+	printFlag bool
+	yesFlag   bool
+	pickFlag  int
+	pushFlag  bool
+	jsonFlag  bool
+	// pushOptionFlags are extra push options (-o) for this run.
+	pushOptionFlags []string
+)
+
+func init() {
+	f := rootCmd.Flags()
+	f.BoolVar(&printFlag, "print", false, "print the suggestions (or the --pick one) to stdout instead of committing")
+	f.BoolVarP(&yesFlag, "yes", "y", false, "commit the first (or --pick) suggestion without prompting")
+	f.IntVar(&pickFlag, "pick", 0, "use the Nth suggestion (1-based); commits unless --print or --json")
+	f.BoolVar(&pushFlag, "push", false, "push after committing (preselected in the interactive confirm screen)")
+	f.BoolVar(&jsonFlag, "json", false, "write suggestions and the result as JSON to stdout")
+	f.StringArrayVar(&pushOptionFlags, "push-option", nil, "push option to send when pushing, e.g. ci.skip (repeatable)")
+}
+
+// runMode describes how the default command runs.
+type runMode struct {
+	// headless skips the TUI.
+	headless bool
+	// commit creates a commit in headless mode.
+	commit bool
+	// fallback is set when headless mode was chosen because no terminal is
+	// attached rather than by a flag.
+	fallback bool
+}
+
+// resolveRunMode validates the headless flags. Without any of them, a
+// non-terminal stdin or stdout falls back to printing the suggestion.
+func resolveRunMode() (runMode, error) {
+	if pickFlag < 0 {
+		return runMode{}, fmt.Errorf("--pick must be 1 or greater")
+	}
+	if printFlag && yesFlag {
+		return runMode{}, fmt.Errorf("--print cannot be combined with --yes")
+	}
+
+	mode := runMode{
+		headless: printFlag || jsonFlag || yesFlag || pickFlag > 0,
+		commit:   yesFlag || (pickFlag > 0 && !printFlag && !jsonFlag),
+	}
+	if !mode.headless && (!isTerminal(os.Stdout) || !isTerminal(os.Stdin)) {
+		mode.headless = true
+		mode.fallback = true
+	}
+	if mode.headless && pushFlag && !mode.commit {
+		return runMode{}, fmt.Errorf("--push requires --yes or --pick when not running interactively")
+	}
+	return mode, nil
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// headlessSuggestion is one entry of the --json output.
+type headlessSuggestion struct {
+	Message    string          `json:"message,omitempty"`
+	Suggestion *llm.Suggestion `json:"suggestion,omitempty"`
+	// Offline is set when the offline heuristic wrote the message because
+	// the provider could not be reached.
+	Offline bool   `json:"offline,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// headlessResult is the --json output.
+type headlessResult struct {
+	Suggestions []headlessSuggestion `json:"suggestions"`
+	Selected    int                  `json:"selected,omitempty"`
+	Message     string               `json:"message,omitempty"`
+	Committed   bool                 `json:"committed"`
+	Pushed      bool                 `json:"pushed"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// runHeadless generates suggestions without the TUI, then prints, commits
+// and pushes according to mode and the root flags.
+func runHeadless(cfg *config.Config, repo *git.Repo, diff string, mode runMode) error {
+	if mode.fallback {
+		fmt.Fprintln(os.Stderr, "Not running in a terminal; printing the suggestions (use --yes to commit the first).")
+	}
+
+	result, err := generateHeadless(context.Background(), cfg, repo, diff)
+	if err == nil && mode.commit {
+		err = commitHeadless(cfg, repo, &result)
+	}
+	if jsonFlag {
+		if err != nil {
+			result.Error = err.Error()
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(result); encErr != nil {
+			return encErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if mode.commit || pickFlag > 0 {
+		fmt.Println(result.Message)
+		return nil
+	}
+	// Without a pick, every ready suggestion is printed, one block each.
+	first := true
+	for _, sug := range result.Suggestions {
+		if sug.Message == "" {
+			continue
+		}
+		if !first {
+			fmt.Println()
+		}
+		fmt.Println(sug.Message)
+		first = false
+	}
+	return nil
+}
+
+// generateHeadless waits for all suggestions and selects the --pick one.
+// Generation stops when ctx is done or on Ctrl-C.
+func generateHeadless(ctx context.Context, cfg *config.Config, repo *git.Repo, diff string) (headlessResult, error) {
+	var result headlessResult
+
+	provider, err := llm.NewProvider(cfg)
+	if err != nil {
+		return result, err
+	}
+
+	n := cfg.Generation.NumSuggestions
+	if n <= 0 {
+		n = 3
+	}
+	if pickFlag > n {
+		n = pickFlag
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	result.Suggestions = make([]headlessSuggestion, n)
+	opts := tui.GenerateOptions(cfg, repo)
+	if amendFlag {
+		opts.PreviousMessage, _ = repo.HeadMessage()
+	}
+	for ev := range llm.GenerateMultiple(ctx, provider, diff, opts, n) {
+		switch {
+		case ev.Err != nil:
+			result.Suggestions[ev.Index].Error = ev.Err.Error()
+		case ev.Done:
+			s := ev.Suggestion
+			result.Suggestions[ev.Index] = headlessSuggestion{Message: s.Message(), Suggestion: &s, Offline: s.Offline}
+		}
+	}
+
+	// Suggestions are numbered in slot order, skipping failed slots.
+	var ready []int
+	var errs []string
+	offline := 0
+	for i, s := range result.Suggestions {
+		if s.Message != "" {
+			ready = append(ready, i)
+			if s.Offline {
+				offline++
+			}
+		} else if s.Error != "" {
+			errs = append(errs, s.Error)
+		}
+	}
+	if offline > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: provider unreachable; %d suggestion(s) come from the offline heuristic, not the model.\n", offline)
+	}
+	if len(ready) == 0 {
+		if len(errs) > 0 {
+			return result, fmt.Errorf("all LLM requests failed: %s", errs[0])
+		}
+		return result, fmt.Errorf("LLM returned no commit messages")
+	}
+
+	pick := pickFlag
+	if pick == 0 {
+		pick = 1
+	}
+	if pick > len(ready) {
+		return result, fmt.Errorf("--pick %d: only %d suggestion(s) available", pick, len(ready))
+	}
+	result.Selected = pick
+	result.Message = result.Suggestions[ready[pick-1]].Message
+	return result, nil
+}
+
+// commitHeadless commits (or amends, with --amend) the selected message with
+// the configured commit options and pushes when --push is set. Progress goes
+// to stderr so stdout only carries the message or JSON.
+func commitHeadless(cfg *config.Config, repo *git.Repo, result *headlessResult) error {
+	opts := tui.CommitOptions(cfg)
+	opts.Amend = amendFlag
+	verb := "Committed"
+	if amendFlag {
+		verb = "Amended"
+	}
+	// An amended commit that was already pushed replaces the remote one.
+	replacesPushed := amendFlag && repo.HeadPushed()
+	if err := repo.CommitWith(result.Message, opts); err != nil {
+		return err
+	}
+	result.Committed = true
+	fmt.Fprintf(os.Stderr, "✓ %s: %s\n", verb, strings.SplitN(result.Message, "\n", 2)[0])
+
+	if pushFlag {
+		push, dest, err := headlessPushOptions(cfg, repo)
+		if err != nil {
+			return err
+		}
+		push.ForceWithLease = replacesPushed
+		if push.Remote == "" && !replacesPushed {
+			if err := syncHeadless(cfg, repo, dest); err != nil {
+				return err
+			}
+		}
+		if err := repo.PushWith(push, nil); err != nil {
+			return err
+		}
+		result.Pushed = true
+		fmt.Fprintf(os.Stderr, "✓ Pushed to %s\n", dest)
+	}
+	return nil
+}
+
+// syncHeadless fetches and, when the upstream has new commits, rebases onto
+// or merges it as configured by push.sync.
+func syncHeadless(cfg *config.Config, repo *git.Repo, upstream string) error {
+	mode := cfg.Push.Sync()
+	if mode == config.SyncOff {
+		return nil
+	}
+	if err := repo.FetchUpstream(); err != nil {
+		return err
+	}
+	_, behind, err := repo.UpstreamDivergence()
+	if err != nil || behind == 0 {
+		return err
+	}
+	if mode == config.SyncMerge {
+		if err := repo.MergeUpstream(upstream); err != nil {
+			return fmt.Errorf("not pushed: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Merged %s (%d new commit(s))\n", upstream, behind)
+		return nil
+	}
+	if err := repo.RebaseOntoUpstream(upstream); err != nil {
+		return fmt.Errorf("not pushed: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "✓ Rebased onto %s (%d new commit(s))\n", upstream, behind)
+	return nil
+}
+
+// headlessPushOptions pushes to the upstream, or sets one on the default
+// remote when the branch has none.
+func headlessPushOptions(cfg *config.Config, repo *git.Repo) (git.PushOptions, string, error) {
+	opts := git.PushOptions{Options: append(slices.Clone(cfg.Push.Options), pushOptionFlags...)}
+	target, err := repo.CurrentPushTarget()
+	if err != nil {
+		return opts, "", err
+	}
+	if target.Upstream != "" {
+		return opts, target.Upstream, nil
+	}
+	remote := target.DefaultRemote(cfg.Push.Remote)
+	if remote == "" {
+		return opts, "", fmt.Errorf("cannot push: no remote configured")
+	}
+	opts.Remote, opts.Branch, opts.SetUpstream = remote, target.Branch, true
+	return opts, remote + "/" + target.Branch, nil
+}
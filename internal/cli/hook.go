@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+	"github.com/spf13/cobra"
+)
+
+const (
+	hookName = "prepare-commit-msg"
+	// hookMarker identifies hooks written by fire-commit.
+	hookMarker = "# Installed by fire-commit"
+	// hookBackupSuffix is appended to a foreign hook replaced with --force;
+	// uninstall restores it.
+	hookBackupSuffix = ".firecommit-backup"
+	// hookTimeout bounds generation inside the hook, whatever the request
+	// timeouts are, so a hanging provider cannot block git commit.
+	hookTimeout = 60 * time.Second
+)
+
+// hookScript delegates to "firecommit hook run" and never blocks a commit
+// when firecommit is not on PATH.
+const hookScript = `#!/bin/sh
+` + hookMarker + ` — remove with "firecommit hook uninstall".
+command -v firecommit >/dev/null 2>&1 || exit 0
+exec firecommit hook run "$@"
+`
+
+var hookForce bool
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the prepare-commit-msg hook for plain git commit",
+	Long: "Install a prepare-commit-msg hook so that plain 'git commit' (or an IDE) " +
+		"opens with a generated message. Merges, squashes, amends and commits " +
+		"with -m or -F are left alone.",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the prepare-commit-msg hook",
+	Args:  cobra.NoArgs,
+	RunE:  runHookInstall,
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the prepare-commit-msg hook",
+	Args:  cobra.NoArgs,
+	RunE:  runHookUninstall,
+}
+
+var hookStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the hook is installed",
+	Args:  cobra.NoArgs,
+	RunE:  runHookStatus,
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:    "run <msgfile> [source] [sha]",
+	Short:  "Entry point called by the prepare-commit-msg hook",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 3),
+	RunE:   runHookRun,
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "replace an existing prepare-commit-msg hook (it is restored on uninstall)")
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookStatusCmd, hookRunCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+// hookPath returns the path of the prepare-commit-msg hook.
+func hookPath() (string, error) {
+	repo, err := openRepo()
+	if err != nil {
+		return "", err
+	}
+	dir, err := repo.HooksDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate hooks directory: %w", err)
+	}
+	return filepath.Join(dir, hookName), nil
+}
+
+// hookState reads the hook at path. It reports whether a file exists and
+// whether fire-commit wrote it.
+func hookState(path string) (exists, ours bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, bytes.Contains(data, []byte(hookMarker)), nil
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	path, err := hookPath()
+	if err != nil {
+		return err
+	}
+	exists, ours, err := hookState(path)
+	if err != nil {
+		return err
+	}
+	if exists && !ours {
+		if !hookForce {
+			return fmt.Errorf("%s already exists and was not installed by fire-commit; use --force to replace it", path)
+		}
+		if err := os.Rename(path, path+hookBackupSuffix); err != nil {
+			return fmt.Errorf("failed to back up existing hook: %w", err)
+		}
+		fmt.Printf("Backed up existing hook to %s\n", path+hookBackupSuffix)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(hookScript), 0o755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.Chmod(path, 0o755); err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s\n", path)
+	return nil
+}
+
+func runHookUninstall(cmd *cobra.Command, args []string) error {
+	path, err := hookPath()
+	if err != nil {
+		return err
+	}
+	exists, ours, err := hookState(path)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		fmt.Println("No prepare-commit-msg hook installed.")
+		return nil
+	}
+	if !ours {
+		return fmt.Errorf("%s was not installed by fire-commit; leaving it in place", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s\n", path)
+
+	backup := path + hookBackupSuffix
+	if _, err := os.Stat(backup); err == nil {
+		if err := os.Rename(backup, path); err != nil {
+			return fmt.Errorf("failed to restore previous hook: %w", err)
+		}
+		fmt.Printf("Restored previous hook from %s\n", backup)
+	}
+	return nil
+}
+
+func runHookStatus(cmd *cobra.Command, args []string) error {
+	path, err := hookPath()
+	if err != nil {
+		return err
+	}
+	exists, ours, err := hookState(path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ours:
+		fmt.Printf("Installed: %s\n", path)
+	case exists:
+		fmt.Printf("Not installed: %s belongs to another tool (use 'firecommit hook install --force' to replace it)\n", path)
+	default:
+		fmt.Printf("Not installed (would be written to %s)\n", path)
+	}
+	return nil
+}
+
+// runHookRun fills in the commit message file for a plain "git commit".
+// Failures are reported on stderr but never abort the commit.
+func runHookRun(cmd *cobra.Command, args []string) error {
+	msgFile := args[0]
+	// A source means git already has a message: -m/-F ("message"), a
+	// template, a merge, a squash, or an amend/-c/-C ("commit").
+	if len(args) > 1 && args[1] != "" {
+		return nil
+	}
+	if err := fillCommitMessage(msgFile); err != nil {
+		fmt.Fprintf(os.Stderr, "firecommit: %v\n", err)
+	}
+	return nil
+}
+
+// fillCommitMessage generates a message for the staged changes and writes it
+// above the comments git put into msgFile.
+func fillCommitMessage(msgFile string) error {
+	if !config.Exists() {
+		return fmt.Errorf("no configuration found, run 'firecommit config setup' first")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	repo := git.Open(repoFlag)
+	if _, err := applyProfile(cfg, repo); err != nil {
+		return err
+	}
+	// One suggestion is enough; the editor lets the user adjust it.
+	cfg.Generation.NumSuggestions = 1
+
+	diff, err := repo.StagedDiff(cfg.Generation.MaxDiffLines)
+	if err != nil {
+		return fmt.Errorf("failed to get diff: %w", err)
+	}
+	if diff == "" {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "firecommit: generating commit message...")
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	result, err := generateHeadless(ctx, cfg, repo, diff)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("no message after %s; leaving the message to you", hookTimeout)
+		}
+		return err
+	}
+
+	existing, err := os.ReadFile(msgFile)
+	if err != nil {
+		return err
+	}
+	content := append([]byte(result.Message+"\n"), existing...)
+	return os.WriteFile(msgFile, content, 0o644)
+}
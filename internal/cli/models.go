@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+	"github.com/lieyanc/fire-commit/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var modelsProvider string
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List models available from a configured provider",
+	Args:  cobra.NoArgs,
+	RunE:  runModels,
+}
+
+func init() {
+	modelsCmd.Flags().StringVar(&modelsProvider, "provider", "", "provider to query (defaults to the selected profile's provider, then default_provider)")
+	rootCmd.AddCommand(modelsCmd)
+}
+
+func runModels(cmd *cobra.Command, args []string) error {
+	if !config.Exists() {
+		return fmt.Errorf("no configuration found, run 'firecommit config setup' first")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	// Select the profile the same way the root command does, so the listed
+	// provider and the current-model marker match what a commit would use.
+	repo, err := openRepo()
+	if err != nil {
+		repo = git.Open(repoFlag)
+	}
+	profile, err := applyProfile(cfg, repo)
+	if err != nil {
+		return err
+	}
+	if profile != "" {
+		fmt.Fprintf(os.Stderr, "Profile: %s\n", profile)
+	}
+
+	name := modelsProvider
+	if name == "" {
+		name = cfg.DefaultProvider
+	}
+	provCfg, ok := cfg.Providers[name]
+	if !ok {
+		return fmt.Errorf("provider %q not configured", name)
+	}
+
+	provider, err := llm.NewListingProvider(name, provCfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	models, err := llm.ListModels(ctx, provider)
+	if err != nil {
+		if errors.Is(err, llm.ErrListModelsUnsupported) {
+			return fmt.Errorf("provider %q does not support listing models", name)
+		}
+		return fmt.Errorf("failed to list models: %w", err)
+	}
+	if len(models) == 0 {
+		fmt.Printf("Provider %q returned no models.\n", name)
+		return nil
+	}
+
+	current := provCfg.Model
+	if current == "" {
+		current = llm.DefaultModel(name)
+	}
+	for _, m := range models {
+		if m == current {
+			fmt.Printf("* %s\n", m)
+		} else {
+			fmt.Printf("  %s\n", m)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+var profileFlag string
+
+// applyProfile selects a profile from --profile, FIRECOMMIT_PROFILE or the
+// repository's path and remotes, and applies it to cfg in memory. It returns
+// the selected profile name, or "" when none applies.
+func applyProfile(cfg *config.Config, repo *git.Repo) (string, error) {
+	name := profileFlag
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv(config.ProfileEnvVar))
+	}
+	if name == "" && len(cfg.Profiles) > 0 && repo.IsGitRepo() {
+		root, _ := repo.RepoRoot()
+		name = cfg.MatchProfile(root, repo.RemoteURLs())
+	}
+	if name == "" {
+		return "", nil
+	}
+	if err := cfg.ApplyProfile(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
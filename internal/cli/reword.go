@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var rewordForce bool
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <revision-range>",
+	Short: "Generate new messages for existing commits and rewrite them",
+	Long: "Generate a new message for each commit in the range from its own diff, " +
+		"review them (accept, edit or skip per commit) and rewrite the branch. " +
+		"A single revision such as HEAD~3 means HEAD~3..HEAD. The range must be " +
+		"free of merge commits; commits after it are replayed unchanged.",
+	Args: cobra.ExactArgs(1),
+	RunE: runReword,
+}
+
+func init() {
+	rewordCmd.Flags().BoolVar(&rewordForce, "force", false, "rewrite commits that are already on the upstream branch or signed")
+	rootCmd.AddCommand(rewordCmd)
+}
+
+func runReword(cmd *cobra.Command, args []string) error {
+	repo, err := openRepo()
+	if err != nil {
+		return err
+	}
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return fmt.Errorf("reword needs an interactive terminal")
+	}
+	if !config.Exists() {
+		return fmt.Errorf("no configuration found, run 'firecommit config setup' first")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if _, err := applyProfile(cfg, repo); err != nil {
+		return err
+	}
+
+	commits, err := repo.RangeCommits(args[0])
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits in %s", args[0])
+	}
+
+	// Everything from the oldest commit up to HEAD is recreated, so the
+	// range must lie on HEAD's linear history.
+	oldest := commits[0]
+	base := ""
+	if len(oldest.Parents) > 0 {
+		base = oldest.Parents[0]
+	}
+	chain, err := repo.CommitChain(base)
+	if err != nil {
+		return err
+	}
+	inChain := make(map[string]bool, len(chain))
+	for _, c := range chain {
+		if len(c.Parents) > 1 {
+			return fmt.Errorf("cannot reword across merge commit %s", c.ShortHash())
+		}
+		inChain[c.Hash] = true
+	}
+	for _, c := range commits {
+		if !inChain[c.Hash] {
+			return fmt.Errorf("commit %s is not on the current branch", c.ShortHash())
+		}
+	}
+
+	if !rewordForce && repo.IsPushed(oldest.Hash) {
+		return fmt.Errorf("commit %s is already on the upstream branch; rewriting it changes published history (use --force to do it anyway)", oldest.ShortHash())
+	}
+
+	// Rewritten commits cannot keep their signatures; with commit.sign they
+	// are signed again with the user's key.
+	if !cfg.Commit.Sign {
+		for _, c := range chain {
+			if !c.Signed {
+				continue
+			}
+			if !rewordForce {
+				return fmt.Errorf("commit %s is signed and rewriting it drops the signature; set commit.sign to re-sign the new commits (or use --force to drop it)", c.ShortHash())
+			}
+			fmt.Fprintf(os.Stderr, "Warning: signatures on the rewritten commits (e.g. %s) will be dropped\n", c.ShortHash())
+			break
+		}
+	}
+
+	items := make([]tui.RewordCommit, len(commits))
+	for i, c := range commits {
+		diff, err := repo.CommitDiff(c.Hash, cfg.Generation.MaxDiffLines)
+		if err != nil {
+			return err
+		}
+		items[i] = tui.RewordCommit{Hash: c.Hash, ShortHash: c.ShortHash(), Message: c.Message, Diff: diff}
+	}
+
+	messages, err := tui.RunReword(cfg, repo, items)
+	if err != nil {
+		return err
+	}
+	if messages == nil {
+		fmt.Println("Cancelled; history unchanged.")
+		return nil
+	}
+	if len(messages) == 0 {
+		fmt.Println("No commits accepted; history unchanged.")
+		return nil
+	}
+
+	newHead, err := repo.RewriteMessages(chain, messages, cfg.Commit.Sign)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite history: %w", err)
+	}
+	fmt.Printf("✓ Reworded %d commit(s); HEAD is now %.7s\n", len(messages), newHead)
+	fmt.Printf("  Undo with: git reset --keep %.7s\n", chain[len(chain)-1].Hash)
+	return nil
+}
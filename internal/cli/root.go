@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/debuglog"
 	"github.com/lieyanc/fire-commit/internal/git"
 	"github.com/lieyanc/fire-commit/internal/tui"
 	"github.com/lieyanc/fire-commit/internal/tui/setup"
@@ -18,37 +20,77 @@ var rootCmd = &cobra.Command{
 	RunE:  runDefault,
 }
 
+func init() {
+	// The flag is consumed in main before Execute so the trace also covers
+	// the background update check; it is declared here for parsing and help.
+	rootCmd.PersistentFlags().Bool("debug", false, "write a redacted JSON-lines trace of LLM, git and update activity to "+debuglog.Path())
+	rootCmd.Flags().BoolVar(&amendFlag, "amend", false, "rewrite the message of HEAD, including any staged changes")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "config profile to use (overrides "+config.ProfileEnvVar+" and automatic matching)")
+	rootCmd.PersistentFlags().StringVarP(&repoFlag, "repo", "C", "", "run in the repository at `path` instead of the current directory")
+	rootCmd.Flags().BoolVar(&submodulesFlag, "submodules", false, "commit changes inside dirty submodules first, then the superproject")
+}
+
+var (
+	amendFlag      bool
+	repoFlag       string
+	submodulesFlag bool
+)
+
+// openRepo returns the repository given by -C/--repo, or the one containing
+// the working directory, opened at its top-level directory.
+func openRepo() (*git.Repo, error) {
+	repo, err := git.OpenRoot(repoFlag)
+	if err != nil {
+		if repoFlag != "" {
+			return nil, fmt.Errorf("%s is not a git repository", repoFlag)
+		}
+		return nil, fmt.Errorf("not a git repository")
+	}
+	return repo, nil
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
 
 func runDefault(cmd *cobra.Command, args []string) error {
+	mode, err := resolveRunMode()
+	if err != nil {
+		return err
+	}
+	if submodulesFlag && amendFlag {
+		return fmt.Errorf("--submodules cannot be combined with --amend")
+	}
+
 	// Show version
-	versionStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#018EEE"))
-	fmt.Println(versionStyle.Render(fmt.Sprintf("🔥 fire-commit %s", appVersion)))
-	fmt.Println()
+	if !mode.headless {
+		versionStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#018EEE"))
+		fmt.Println(versionStyle.Render(fmt.Sprintf("🔥 fire-commit %s", appVersion)))
+		fmt.Println()
+	}
 
 	// Step 1: Check or create config
 	var cfg *config.Config
 	if !config.Exists() {
-		var err error
+		if mode.headless {
+			return fmt.Errorf("no configuration found, run 'firecommit config setup' first")
+		}
 		cfg, err = setup.RunWizard()
 		if err != nil {
 			return fmt.Errorf("setup failed: %w", err)
 		}
 	} else {
-		var err error
 		cfg, err = config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 	}
 
-	// Step 1.5: Check if config needs migration
-	if config.NeedsMigration(cfg) {
-		var err error
+	// Step 1.5: Check if config needs migration. New fields keep their
+	// defaults when there is no terminal to ask.
+	if config.NeedsMigration(cfg) && !mode.headless {
 		cfg, err = setup.RunMigration(cfg)
 		if err != nil {
 			return fmt.Errorf("config migration failed: %w", err)
@@ -56,47 +98,151 @@ func runDefault(cmd *cobra.Command, args []string) error {
 	}
 
 	// Step 2: Check git repo
-	if !git.IsGitRepo() {
-		return fmt.Errorf("not a git repository")
+	repo, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	profile, err := applyProfile(cfg, repo)
+	if err != nil {
+		return err
+	}
+	if profile != "" && !mode.headless {
+		fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color("#52B0FF")).Render("   Profile: " + profile))
+		fmt.Println()
 	}
 
 	// Step 3: Get diff
-	staged, err := git.HasStagedChanges()
+	switch {
+	case amendFlag:
+		err = runAmend(cfg, repo, mode)
+	case submodulesFlag:
+		err = runSubmodules(cfg, repo, mode)
+	default:
+		err = runCommit(cfg, repo, mode)
+	}
+	if errors.Is(err, errCancelled) {
+		return nil
+	}
+	return err
+}
+
+// errCancelled is returned by runCommit when the user quits the TUI without
+// committing.
+var errCancelled = errors.New("cancelled")
+
+// nothingToCommitError is returned by runCommit when the staging policy
+// leaves nothing to commit.
+type nothingToCommitError string
+
+func (e nothingToCommitError) Error() string { return string(e) }
+
+// runCommit generates a message for the changes in repo, staging them first
+// according to the staging policy, and commits it.
+func runCommit(cfg *config.Config, repo *git.Repo, mode runMode) error {
+	staged, err := repo.HasStagedChanges()
 	if err != nil {
 		return fmt.Errorf("failed to check staged changes: %w", err)
 	}
+	unstaged, err := repo.HasUnstagedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check unstaged changes: %w", err)
+	}
+	untracked, err := repo.HasUntrackedFiles()
+	if err != nil {
+		return fmt.Errorf("failed to check untracked files: %w", err)
+	}
+	if !staged && !unstaged && !untracked {
+		return nothingToCommitError("no changes to commit")
+	}
 
-	if !staged {
-		unstaged, err := git.HasUnstagedChanges()
-		if err != nil {
-			return fmt.Errorf("failed to check unstaged changes: %w", err)
-		}
-		untracked, err := git.HasUntrackedFiles()
-		if err != nil {
-			return fmt.Errorf("failed to check untracked files: %w", err)
-		}
+	// Runs that only print suggestions leave the index untouched and
+	// describe the working tree instead.
+	previewOnly := mode.headless && !mode.commit
 
-		if !unstaged && !untracked {
-			return fmt.Errorf("no changes to commit")
-		}
+	policy := cfg.Commit.Staging()
+	if policy == config.StagingAsk && mode.headless {
+		// Without a terminal to ask, never pick up untracked files.
+		policy = config.StagingTracked
+	}
+	if policy == config.StagingAsk && (unstaged || untracked) {
+		return runTUI(cfg, repo, "", "", tui.Options{Push: pushFlag, SelectFiles: true, PushOptions: pushOptionFlags})
+	}
 
-		// Auto-stage all changes
-		if err := git.StageAll(); err != nil {
-			return fmt.Errorf("failed to stage changes: %w", err)
+	if !staged && !previewOnly {
+		switch policy {
+		case config.StagingAll:
+			if err := repo.StageAll(); err != nil {
+				return fmt.Errorf("failed to stage changes: %w", err)
+			}
+		case config.StagingTracked:
+			if err := repo.StageTracked(); err != nil {
+				return fmt.Errorf("failed to stage changes: %w", err)
+			}
+			if staged, _ := repo.HasStagedChanges(); !staged {
+				return nothingToCommitError("no changes to tracked files; stage new files with git add (staging_policy: tracked)")
+			}
+		case config.StagingStagedOnly:
+			return nothingToCommitError("nothing staged; stage changes with git add first (staging_policy: staged-only)")
 		}
 	}
 
-	diff, err := git.StagedDiff(cfg.Generation.MaxDiffLines)
+	var diff string
+	if previewOnly && !staged {
+		diff, err = repo.WorkingTreeDiff(cfg.Generation.MaxDiffLines)
+	} else {
+		diff, err = repo.StagedDiff(cfg.Generation.MaxDiffLines)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get diff: %w", err)
 	}
 
 	if diff == "" {
-		return fmt.Errorf("empty diff — nothing to commit")
+		return nothingToCommitError("empty diff — nothing to commit")
 	}
 
-	stat, _ := git.DiffStat()
+	if mode.headless {
+		return runHeadless(cfg, repo, diff, mode)
+	}
+
+	stat, _ := repo.DiffStat()
 
 	// Step 4: Launch TUI
-	return tui.Run(cfg, diff, stat)
+	return runTUI(cfg, repo, diff, stat, tui.Options{Push: pushFlag, PushOptions: pushOptionFlags})
+}
+
+// runAmend generates a message for HEAD plus the staged changes and amends
+// HEAD with it. Unstaged changes are left alone.
+func runAmend(cfg *config.Config, repo *git.Repo, mode runMode) error {
+	if !repo.HasHead() {
+		return fmt.Errorf("nothing to amend: the repository has no commits yet")
+	}
+
+	diff, err := repo.AmendDiff(cfg.Generation.MaxDiffLines)
+	if err != nil {
+		return fmt.Errorf("failed to get diff: %w", err)
+	}
+	if diff == "" {
+		return fmt.Errorf("empty diff — HEAD has no changes to describe")
+	}
+
+	if mode.headless {
+		return runHeadless(cfg, repo, diff, mode)
+	}
+
+	stat, _ := repo.AmendDiffStat()
+	return runTUI(cfg, repo, diff, stat, tui.Options{Push: pushFlag, Amend: true, PushOptions: pushOptionFlags})
+}
+
+// runTUI runs the TUI and returns errCancelled when the user quits without
+// committing.
+func runTUI(cfg *config.Config, repo *git.Repo, diff, stat string, opts tui.Options) error {
+	committed, err := tui.Run(cfg, repo, diff, stat, opts)
+	if err != nil {
+		return err
+	}
+	if !committed {
+		return errCancelled
+	}
+	return nil
 }
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+	"github.com/lieyanc/fire-commit/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split the staged changes into several commits",
+	Long: "Let the model group the staged hunks into logical commits, each with " +
+		"its own message. Adjust the grouping and messages, then the groups are " +
+		"committed in order by restaging each one. Unstaged changes are left alone.",
+	Args: cobra.NoArgs,
+	RunE: runSplit,
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+}
+
+func runSplit(cmd *cobra.Command, args []string) error {
+	repo, err := openRepo()
+	if err != nil {
+		return err
+	}
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return fmt.Errorf("split needs an interactive terminal")
+	}
+	if !config.Exists() {
+		return fmt.Errorf("no configuration found, run 'firecommit config setup' first")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if _, err := applyProfile(cfg, repo); err != nil {
+		return err
+	}
+
+	diffs, err := repo.StagedFileDiffs()
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		return fmt.Errorf("no staged changes to split")
+	}
+
+	var units []git.PatchUnit
+	var items []tui.SplitUnit
+	for i, d := range diffs {
+		if len(d.Hunks) == 0 {
+			units = append(units, git.PatchUnit{File: i, Hunk: -1})
+			items = append(items, tui.SplitUnit{Path: d.Path, Label: "whole file", Patch: d.Raw})
+			continue
+		}
+		for j, h := range d.Hunks {
+			units = append(units, git.PatchUnit{File: i, Hunk: j})
+			items = append(items, tui.SplitUnit{
+				Path:  d.Path,
+				Label: h.Header,
+				Patch: git.BuildPatch(diffs, []git.PatchUnit{{File: i, Hunk: j}}),
+			})
+		}
+	}
+
+	groups, err := tui.RunSplit(cfg, repo, items)
+	if err != nil {
+		return err
+	}
+	if groups == nil {
+		fmt.Println("Cancelled; nothing committed.")
+		return nil
+	}
+
+	// Start from an empty index and restage one group per commit. The
+	// saved index brings back the changes not yet committed if one fails.
+	saved, err := repo.WriteTree()
+	if err != nil {
+		return err
+	}
+	paths := make([]string, len(diffs))
+	for i, d := range diffs {
+		paths[i] = d.Path
+	}
+	if err := repo.UnstagePaths(paths); err != nil {
+		return err
+	}
+	opts := tui.CommitOptions(cfg)
+	for i, g := range groups {
+		selected := make([]git.PatchUnit, len(g.Hunks))
+		for j, u := range g.Hunks {
+			selected[j] = units[u]
+		}
+		subject, _, _ := strings.Cut(g.Message, "\n")
+		err := repo.ApplyCached(git.BuildPatch(diffs, selected))
+		if err == nil {
+			err = repo.CommitWith(g.Message, opts)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ [%d/%d] %s\n", i+1, len(groups), subject)
+			// The saved index holds every group; against the new HEAD it
+			// stages exactly the groups that were not committed.
+			if restoreErr := repo.ReadTree(saved); restoreErr != nil {
+				return fmt.Errorf("%w\n%d of %d commits created; restoring the index failed (%v), the remaining changes are in the working tree", err, i, len(groups), restoreErr)
+			}
+			return fmt.Errorf("%w\n%d of %d commits created; the remaining changes are staged again", err, i, len(groups))
+		}
+		fmt.Printf("✓ [%d/%d] %s\n", i+1, len(groups), subject)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+// runSubmodules commits the changes inside each dirty submodule of repo,
+// nested ones first, and then the superproject, which picks up the new
+// submodule commits. Cancelling one commit stops the walk.
+func runSubmodules(cfg *config.Config, repo *git.Repo, mode runMode) error {
+	committed, err := commitSubmodules(cfg, repo, mode, "")
+	if errors.Is(err, errCancelled) {
+		fmt.Printf("Cancelled; %d submodule commit(s) made, superproject not committed.\n", committed)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if committed > 0 {
+		announceRepo("Superproject", mode)
+	}
+	return runCommit(cfg, repo, mode)
+}
+
+// commitSubmodules runs a commit for every dirty submodule of repo below
+// prefix and returns how many it ran. Submodules with nothing to commit
+// under the staging policy, e.g. only untracked files with staging_policy:
+// tracked, are skipped.
+func commitSubmodules(cfg *config.Config, repo *git.Repo, mode runMode, prefix string) (int, error) {
+	subs, err := repo.DirtySubmodules()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, sub := range subs {
+		name := path.Join(prefix, sub.Path)
+		nested, err := commitSubmodules(cfg, sub.Repo, mode, name)
+		count += nested
+		if err != nil {
+			return count, err
+		}
+
+		announceRepo("Submodule: "+name, mode)
+		err = runCommit(cfg, sub.Repo, mode)
+		var nothing nothingToCommitError
+		if errors.As(err, &nothing) {
+			notifyRepo("Skipped: "+nothing.Error(), mode)
+			continue
+		}
+		if err != nil {
+			return count, fmt.Errorf("submodule %s: %w", name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// announceRepo tells the user which repository the next commit is for. In
+// headless mode it goes to stderr so stdout only carries messages or JSON.
+func announceRepo(label string, mode runMode) {
+	if mode.headless {
+		fmt.Fprintf(os.Stderr, "── %s\n", label)
+		return
+	}
+	fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color("#52B0FF")).Render("   " + label))
+	fmt.Println()
+}
+
+// notifyRepo reports what happened to a repository of the walk, on stderr
+// in headless mode.
+func notifyRepo(text string, mode runMode) {
+	if mode.headless {
+		fmt.Fprintln(os.Stderr, text)
+		return
+	}
+	fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color("#666666")).Render("   " + text))
+	fmt.Println()
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/lieyanc/fire-commit/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -20,8 +19,9 @@ func init() {
 }
 
 func runTag(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
-		return fmt.Errorf("not a git repository")
+	repo, err := openRepo()
+	if err != nil {
+		return err
 	}
 
 	tag := strings.TrimSpace(args[0])
@@ -32,12 +32,12 @@ func runTag(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("tag must start with 'v' to trigger release workflow, e.g. v1.2.3")
 	}
 
-	if err := git.Tag(tag); err != nil {
+	if err := repo.Tag(tag); err != nil {
 		return err
 	}
 	fmt.Printf("Created tag: %s\n", tag)
 
-	if err := git.PushTag(tag); err != nil {
+	if err := repo.PushTag(tag); err != nil {
 		return err
 	}
 	fmt.Printf("Pushed tag: %s\n", tag)
@@ -2,15 +2,51 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // ProviderConfig holds credentials and settings for a single LLM provider.
+// The API key is taken from APIKey, or else the environment variable named by
+// APIKeyEnv, or else the stdout of APIKeyCmd (see ResolveAPIKey). The exec
+// provider uses Command instead and treats the API key as optional.
 type ProviderConfig struct {
-	APIKey  string `yaml:"api_key"`
-	Model   string `yaml:"model"`
-	BaseURL string `yaml:"base_url,omitempty"`
+	// Type selects the provider implementation (openai, anthropic,
+	// openai_compat, ollama, exec). Built-in provider names imply it.
+	Type      string `yaml:"type,omitempty"`
+	APIKey    string `yaml:"api_key,omitempty"`
+	APIKeyEnv string `yaml:"api_key_env,omitempty"`
+	APIKeyCmd string `yaml:"api_key_cmd,omitempty"`
+	Model     string `yaml:"model"`
+	BaseURL   string `yaml:"base_url,omitempty"`
+	// Command is the shell command run by the exec provider.
+	Command string `yaml:"command,omitempty"`
+
+	Transport TransportConfig `yaml:"transport,omitempty"`
+}
+
+// TransportConfig holds HTTP settings applied to a provider's API client.
+// The zero value uses the SDK defaults.
+type TransportConfig struct {
+	// Proxy is an http(s) proxy URL. When empty, HTTPS_PROXY/NO_PROXY apply.
+	Proxy string `yaml:"proxy,omitempty"`
+	// CACertFile is a PEM bundle trusted in addition to the system roots.
+	CACertFile string `yaml:"ca_cert_file,omitempty"`
+	// InsecureSkipVerify disables TLS verification. Only for lab gateways.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty"`
+	// ConnectTimeout bounds TCP connection setup, e.g. "5s".
+	ConnectTimeout time.Duration `yaml:"connect_timeout,omitempty"`
+	// Timeout bounds each whole request including the streamed response.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Headers are extra HTTP headers sent with every request.
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// IsZero reports whether no transport settings are configured.
+func (t TransportConfig) IsZero() bool {
+	return t.Proxy == "" && t.CACertFile == "" && !t.InsecureSkipVerify &&
+		t.ConnectTimeout == 0 && t.Timeout == 0 && len(t.Headers) == 0
 }
 
 // GenerationConfig holds generation-related settings.
@@ -18,11 +54,116 @@ type GenerationConfig struct {
 	NumSuggestions int    `yaml:"num_suggestions"`
 	Language       string `yaml:"language"`
 	MaxDiffLines   int    `yaml:"max_diff_lines"`
+	// Convention is the message style: "conventional" (default) for
+	// Conventional Commits headers or "plain" for imperative subject lines.
+	Convention string `yaml:"convention,omitempty"`
+	// OfflineFallback switches to the offline heuristic provider when the
+	// configured provider cannot be reached.
+	OfflineFallback bool `yaml:"offline_fallback"`
+	// StructuredOutput requests JSON suggestions with type, scope, body,
+	// footers and rationale instead of a single header line.
+	StructuredOutput bool `yaml:"structured_output"`
+	// HistoryExamples is the number of recent commit subjects included in
+	// the prompt as examples of the repository's conventions (0 disables).
+	HistoryExamples int `yaml:"history_examples"`
+	// MaxConcurrency limits how many suggestion requests run at once; the
+	// rest are queued (0 = no limit).
+	MaxConcurrency int `yaml:"max_concurrency,omitempty"`
+	// RequestTimeout bounds each suggestion request, e.g. "2m" (0 = none).
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// FirstTokenTimeout fails a request that streams nothing within this
+	// duration, e.g. "30s" (0 = none).
+	FirstTokenTimeout time.Duration `yaml:"first_token_timeout"`
+}
+
+// Staging policies for CommitConfig.StagingPolicy. They apply when fire-commit
+// starts with unstaged or untracked changes.
+const (
+	// StagingAll stages everything, including untracked files (git add -A).
+	StagingAll = "all"
+	// StagingTracked stages changes to tracked files only (git add -u).
+	StagingTracked = "tracked"
+	// StagingAsk lets the user pick files in the TUI (the default).
+	StagingAsk = "ask"
+	// StagingStagedOnly never stages anything; only the index is committed.
+	StagingStagedOnly = "staged-only"
+)
+
+// StagingPolicies lists the accepted staging policies.
+func StagingPolicies() []string {
+	return []string{StagingAsk, StagingAll, StagingTracked, StagingStagedOnly}
+}
+
+// CommitConfig holds settings for how commits are created.
+type CommitConfig struct {
+	// StagingPolicy decides what gets staged before generating: "ask"
+	// (default), "all", "tracked" or "staged-only".
+	StagingPolicy string `yaml:"staging_policy,omitempty"`
+	// Sign signs commits with the GPG or SSH key configured in git (-S).
+	Sign bool `yaml:"sign,omitempty"`
+	// Signoff adds a Signed-off-by trailer (--signoff).
+	Signoff bool `yaml:"signoff,omitempty"`
+	// NoVerify skips the pre-commit and commit-msg hooks (--no-verify).
+	NoVerify bool `yaml:"no_verify,omitempty"`
+	// CoAuthors and Reviewers are saved "Name <email>" entries offered as
+	// Co-authored-by and Reviewed-by trailers on the confirm screen.
+	CoAuthors []string `yaml:"co_authors,omitempty"`
+	Reviewers []string `yaml:"reviewers,omitempty"`
+	// Trailers are "Key: value" lines added to every commit.
+	Trailers []string `yaml:"trailers,omitempty"`
+}
+
+// Staging returns the effective staging policy.
+func (c CommitConfig) Staging() string {
+	for _, p := range StagingPolicies() {
+		if c.StagingPolicy == p {
+			return p
+		}
+	}
+	return StagingAsk
+}
+
+// Sync modes for PushConfig.SyncMode: how a branch that is behind its
+// upstream is brought up to date before pushing.
+const (
+	// SyncRebase rebases the local commits onto the upstream (the default).
+	SyncRebase = "rebase"
+	// SyncMerge merges the upstream into the branch.
+	SyncMerge = "merge"
+	// SyncOff pushes without fetching first.
+	SyncOff = "off"
+)
+
+// SyncModes lists the accepted sync modes.
+func SyncModes() []string {
+	return []string{SyncRebase, SyncMerge, SyncOff}
+}
+
+// PushConfig holds settings for pushing after a commit.
+type PushConfig struct {
+	// Remote is the remote a branch without upstream is pushed to; it falls
+	// back to "origin" or the only remote.
+	Remote string `yaml:"remote,omitempty"`
+	// Options are push options sent with -o, e.g. "ci.skip".
+	Options []string `yaml:"options,omitempty"`
+	// SyncMode is the preselected way to catch up with the upstream when it
+	// has new commits: "rebase" (default), "merge" or "off".
+	SyncMode string `yaml:"sync,omitempty"`
+}
+
+// Sync returns the effective sync mode.
+func (c PushConfig) Sync() string {
+	for _, s := range SyncModes() {
+		if c.SyncMode == s {
+			return s
+		}
+	}
+	return SyncRebase
 }
 
 // CurrentConfigVersion is bumped when new config fields are added.
 // Existing configs with a lower version will trigger a migration prompt.
-const CurrentConfigVersion = 2
+const CurrentConfigVersion = 6
 
 // Config is the top-level configuration.
 type Config struct {
@@ -30,6 +171,8 @@ type Config struct {
 	DefaultProvider string                    `yaml:"default_provider"`
 	Providers       map[string]ProviderConfig `yaml:"providers"`
 	Generation      GenerationConfig          `yaml:"generation"`
+	Commit          CommitConfig              `yaml:"commit,omitempty"`
+	Push            PushConfig                `yaml:"push,omitempty"`
 	UpdateChannel   string                    `yaml:"update_channel"`
 	// AutoUpdate controls automatic update behavior for non-dev builds.
 	// "y" = show update notice (default for non-dev builds)
@@ -41,6 +184,9 @@ type Config struct {
 	// UpdateCache controls whether background update checks use cached state
 	// (ETag + adaptive interval). Default false means check every run.
 	UpdateCache bool `yaml:"update_cache"`
+	// Profiles are named overrides selected with --profile,
+	// FIRECOMMIT_PROFILE or by matching the repository (see MatchProfile).
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
 }
 
 // NeedsMigration returns true if the config was created with an older version
@@ -55,9 +201,13 @@ func DefaultConfig() *Config {
 		DefaultProvider: "",
 		Providers:       make(map[string]ProviderConfig),
 		Generation: GenerationConfig{
-			NumSuggestions: 3,
-			Language:       "en",
-			MaxDiffLines:   4096,
+			NumSuggestions:    3,
+			Language:          "en",
+			MaxDiffLines:      4096,
+			OfflineFallback:   true,
+			HistoryExamples:   5,
+			RequestTimeout:    2 * time.Minute,
+			FirstTokenTimeout: 30 * time.Second,
 		},
 		UpdateChannel: "latest",
 		UpdateCache:   false,
@@ -84,6 +234,7 @@ func Load() (*Config, error) {
 }
 
 // Save writes the config to disk, creating parent directories as needed.
+// The file is readable only by the owner since it may contain API keys.
 func Save(cfg *Config) error {
 	if err := os.MkdirAll(ConfigDir(), 0o755); err != nil {
 		return err
@@ -92,5 +243,10 @@ func Save(cfg *Config) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(ConfigPath(), data, 0o644)
+	if err := os.WriteFile(ConfigPath(), data, 0o600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file; tighten configs written
+	// by older versions with 0644.
+	return os.Chmod(ConfigPath(), 0o600)
 }
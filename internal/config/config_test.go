@@ -0,0 +1,93 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCommitStagingPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{"", StagingAsk},
+		{"all", StagingAll},
+		{"tracked", StagingTracked},
+		{"staged-only", StagingStagedOnly},
+		{"bogus", StagingAsk},
+	}
+	for _, tt := range tests {
+		if got := (CommitConfig{StagingPolicy: tt.policy}).Staging(); got != tt.want {
+			t.Errorf("Staging(%q) = %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestPushSyncMode(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"", SyncRebase},
+		{"rebase", SyncRebase},
+		{"merge", SyncMerge},
+		{"off", SyncOff},
+		{"pull", SyncRebase},
+	}
+	for _, tt := range tests {
+		if got := (PushConfig{SyncMode: tt.mode}).Sync(); got != tt.want {
+			t.Errorf("Sync(%q) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestZeroHistoryExamplesSurvivesSave(t *testing.T) {
+	t.Setenv("FIRECOMMIT_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+
+	cfg := DefaultConfig()
+	if cfg.Generation.HistoryExamples == 0 {
+		t.Fatalf("history examples should be on by default")
+	}
+	cfg.Generation.HistoryExamples = 0
+	if err := Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Generation.HistoryExamples != 0 {
+		t.Fatalf("HistoryExamples = %d after reload, want 0", loaded.Generation.HistoryExamples)
+	}
+}
+
+func TestZeroTimeoutsSurviveSave(t *testing.T) {
+	t.Setenv("FIRECOMMIT_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+
+	cfg := DefaultConfig()
+	cfg.Generation.RequestTimeout = 0
+	cfg.Generation.FirstTokenTimeout = 0
+	if err := Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Generation.RequestTimeout != 0 || loaded.Generation.FirstTokenTimeout != 0 {
+		t.Fatalf("timeouts after reload = %s/%s, want 0/0",
+			loaded.Generation.RequestTimeout, loaded.Generation.FirstTokenTimeout)
+	}
+
+	cfg.Generation.RequestTimeout = 90 * time.Second
+	if err := Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = Load(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Generation.RequestTimeout != 90*time.Second {
+		t.Fatalf("RequestTimeout after reload = %s, want 1m30s", loaded.Generation.RequestTimeout)
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// API key sources reported by ProviderConfig.APIKeySource.
+const (
+	KeySourceNone    = "none"
+	KeySourceConfig  = "config"
+	KeySourceEnv     = "env"
+	KeySourceCommand = "command"
+)
+
+var (
+	keyCmdMu    sync.Mutex
+	keyCmdCache = make(map[string]string)
+)
+
+// APIKeySource reports where the API key for this provider comes from.
+func (p ProviderConfig) APIKeySource() string {
+	switch {
+	case p.APIKey != "":
+		return KeySourceConfig
+	case p.APIKeyEnv != "":
+		return KeySourceEnv
+	case p.APIKeyCmd != "":
+		return KeySourceCommand
+	default:
+		return KeySourceNone
+	}
+}
+
+// ResolveAPIKey returns the API key from the configured source. Credential
+// commands run at most once per process; their output is cached in memory.
+func (p ProviderConfig) ResolveAPIKey() (string, error) {
+	switch p.APIKeySource() {
+	case KeySourceConfig:
+		return p.APIKey, nil
+	case KeySourceEnv:
+		key := strings.TrimSpace(os.Getenv(p.APIKeyEnv))
+		if key == "" {
+			return "", fmt.Errorf("environment variable %s is not set", p.APIKeyEnv)
+		}
+		return key, nil
+	case KeySourceCommand:
+		return runKeyCommand(p.APIKeyCmd)
+	default:
+		return "", fmt.Errorf("no API key configured")
+	}
+}
+
+func runKeyCommand(command string) (string, error) {
+	keyCmdMu.Lock()
+	defer keyCmdMu.Unlock()
+
+	if key, ok := keyCmdCache[command]; ok {
+		return key, nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("api_key_cmd failed: %s", msg)
+	}
+
+	// Tools like `pass` print the secret on the first line and metadata after it.
+	key := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	if key == "" {
+		return "", fmt.Errorf("api_key_cmd printed an empty key")
+	}
+	keyCmdCache[command] = key
+	return key, nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestResolveAPIKeyPrefersPlaintextThenEnv(t *testing.T) {
+	t.Setenv("FIRECOMMIT_TEST_KEY", "env-key")
+
+	p := ProviderConfig{APIKey: "plain-key", APIKeyEnv: "FIRECOMMIT_TEST_KEY"}
+	if got, err := p.ResolveAPIKey(); err != nil || got != "plain-key" {
+		t.Fatalf("ResolveAPIKey() = %q, %v, want plain-key", got, err)
+	}
+
+	p.APIKey = ""
+	if got, err := p.ResolveAPIKey(); err != nil || got != "env-key" {
+		t.Fatalf("ResolveAPIKey() = %q, %v, want env-key", got, err)
+	}
+	if p.APIKeySource() != KeySourceEnv {
+		t.Fatalf("APIKeySource() = %q, want %q", p.APIKeySource(), KeySourceEnv)
+	}
+}
+
+func TestResolveAPIKeyMissingEnv(t *testing.T) {
+	t.Setenv("FIRECOMMIT_TEST_KEY", "")
+
+	p := ProviderConfig{APIKeyEnv: "FIRECOMMIT_TEST_KEY"}
+	if _, err := p.ResolveAPIKey(); err == nil {
+		t.Fatalf("expected error for unset environment variable")
+	}
+}
+
+func TestResolveAPIKeyCommandUsesFirstLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	p := ProviderConfig{APIKeyCmd: "printf 'cmd-key\\nurl: example.com\\n'"}
+	got, err := p.ResolveAPIKey()
+	if err != nil {
+		t.Fatalf("ResolveAPIKey() error = %v", err)
+	}
+	if got != "cmd-key" {
+		t.Fatalf("ResolveAPIKey() = %q, want cmd-key", got)
+	}
+}
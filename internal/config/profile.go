@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ProfileEnvVar selects a profile when --profile is not given.
+const ProfileEnvVar = "FIRECOMMIT_PROFILE"
+
+// Profile bundles settings that override the top-level config for one run.
+// Empty fields keep the top-level value.
+type Profile struct {
+	Provider       string `yaml:"provider,omitempty"`
+	Model          string `yaml:"model,omitempty"`
+	Language       string `yaml:"language,omitempty"`
+	NumSuggestions int    `yaml:"num_suggestions,omitempty"`
+	Convention     string `yaml:"convention,omitempty"`
+
+	// Paths are repository root globs that select this profile
+	// automatically, e.g. "~/work/*". "*" also matches "/".
+	Paths []string `yaml:"paths,omitempty"`
+	// Remotes are remote URL globs that select this profile automatically,
+	// e.g. "*github.com:acme/*".
+	Remotes []string `yaml:"remotes,omitempty"`
+}
+
+// ProfileNames returns the configured profile names, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MatchProfile returns the first profile, in name order, whose path or
+// remote patterns match the repository. It returns "" when none match.
+func (c *Config) MatchProfile(repoRoot string, remoteURLs []string) string {
+	for _, name := range c.ProfileNames() {
+		p := c.Profiles[name]
+		for _, pattern := range p.Paths {
+			if repoRoot != "" && globMatch(expandHome(pattern), filepath.ToSlash(repoRoot)) {
+				return name
+			}
+		}
+		for _, pattern := range p.Remotes {
+			for _, u := range remoteURLs {
+				if globMatch(pattern, u) {
+					return name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// ApplyProfile overlays the named profile onto c in memory. The result must
+// not be saved, since it would persist the overrides as top-level settings.
+func (c *Config) ApplyProfile(name string) error {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	if p.Provider != "" {
+		c.DefaultProvider = p.Provider
+	}
+	if p.Model != "" {
+		providers := make(map[string]ProviderConfig, len(c.Providers))
+		for k, v := range c.Providers {
+			providers[k] = v
+		}
+		prov := providers[c.DefaultProvider]
+		prov.Model = p.Model
+		providers[c.DefaultProvider] = prov
+		c.Providers = providers
+	}
+	if p.Language != "" {
+		c.Generation.Language = p.Language
+	}
+	if p.NumSuggestions > 0 {
+		c.Generation.NumSuggestions = p.NumSuggestions
+	}
+	if p.Convention != "" {
+		c.Generation.Convention = p.Convention
+	}
+	return nil
+}
+
+// expandHome replaces a leading "~" with the user's home directory.
+func expandHome(pattern string) string {
+	if pattern != "~" && !strings.HasPrefix(pattern, "~/") {
+		return filepath.ToSlash(pattern)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return pattern
+	}
+	return filepath.ToSlash(home) + pattern[1:]
+}
+
+// globMatch matches s against a pattern where "*" matches any run of
+// characters (including "/") and "?" matches one character.
+func globMatch(pattern, s string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	return err == nil && re.MatchString(s)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cfg := DefaultConfig()
+	cfg.Profiles = map[string]Profile{
+		"oss":  {Remotes: []string{"*github.com?me/*"}},
+		"work": {Paths: []string{"~/work/*"}, Remotes: []string{"*git.acme.corp*"}},
+	}
+
+	tests := []struct {
+		name    string
+		root    string
+		remotes []string
+		want    string
+	}{
+		{"path glob", filepath.Join(home, "work", "billing"), nil, "work"},
+		{"nested path", filepath.Join(home, "work", "team", "api"), nil, "work"},
+		{"remote pattern", "/src/api", []string{"git@git.acme.corp:platform/api.git"}, "work"},
+		{"ssh remote", "/src/tool", []string{"git@github.com:me/tool.git"}, "oss"},
+		{"https remote", "/src/tool", []string{"https://github.com/me/tool"}, "oss"},
+		{"no match", filepath.Join(home, "personal"), []string{"https://gitlab.com/me/x.git"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.MatchProfile(tt.root, tt.remotes); got != tt.want {
+				t.Fatalf("MatchProfile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProfileOverridesWithoutMutatingProviders(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DefaultProvider = "openai"
+	cfg.Providers["openai"] = ProviderConfig{Model: "gpt-5-nano"}
+	cfg.Providers["anthropic"] = ProviderConfig{Model: "claude-haiku-4-5"}
+	original := cfg.Providers
+	cfg.Profiles = map[string]Profile{
+		"work": {Provider: "anthropic", Model: "claude-sonnet-4-5", Language: "de", NumSuggestions: 5, Convention: "plain"},
+	}
+
+	if err := cfg.ApplyProfile("work"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	if cfg.DefaultProvider != "anthropic" || cfg.Providers["anthropic"].Model != "claude-sonnet-4-5" {
+		t.Fatalf("provider = %q / %q", cfg.DefaultProvider, cfg.Providers["anthropic"].Model)
+	}
+	if cfg.Generation.Language != "de" || cfg.Generation.NumSuggestions != 5 || cfg.Generation.Convention != "plain" {
+		t.Fatalf("generation = %+v", cfg.Generation)
+	}
+	if original["anthropic"].Model != "claude-haiku-4-5" {
+		t.Fatalf("ApplyProfile mutated the original providers map")
+	}
+
+	if err := cfg.ApplyProfile("missing"); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+}
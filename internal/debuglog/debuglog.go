@@ -0,0 +1,157 @@
+// Package debuglog writes an opt-in structured trace of LLM requests, git
+// invocations and update checks as JSON lines. Secrets are redacted before
+// anything reaches the file.
+package debuglog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// EnvVar enables debug logging when set to a non-empty value other than "0"/"false".
+const EnvVar = "FIRECOMMIT_DEBUG"
+
+const redacted = "[REDACTED]"
+
+var (
+	mu   sync.Mutex
+	file *os.File
+)
+
+// Path returns the log file location under the XDG state directory.
+func Path() string {
+	return filepath.Join(xdg.StateHome, "firecommit", "debug.log")
+}
+
+// EnabledByEnv reports whether FIRECOMMIT_DEBUG asks for debug logging.
+func EnabledByEnv() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvVar))) {
+	case "", "0", "false", "no", "off":
+		return false
+	default:
+		return true
+	}
+}
+
+// Enable opens the log file for appending. Calling it again is a no-op.
+func Enable() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file != nil {
+		return nil
+	}
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	file = f
+	return nil
+}
+
+// Enabled reports whether events are being recorded.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return file != nil
+}
+
+// Close flushes and closes the log file.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// Log appends one event. Fields whose name looks like a credential are
+// redacted. It is safe to call when logging is disabled.
+func Log(event string, fields map[string]any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return
+	}
+
+	record := make(map[string]any, len(fields)+2)
+	for k, v := range fields {
+		if isSensitiveName(k) {
+			v = redacted
+		}
+		record[k] = v
+	}
+	record["ts"] = time.Now().Format(time.RFC3339Nano)
+	record["event"] = event
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		data, _ = json.Marshal(map[string]any{"ts": record["ts"], "event": event, "marshal_error": err.Error()})
+	}
+	file.Write(append(data, '\n'))
+}
+
+// RedactHeaders flattens headers for logging, replacing credential values.
+func RedactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if isSensitiveName(k) {
+			out[k] = redacted
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// RedactURL hides credential-like query parameters and URL user info.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	c := *u
+	if c.User != nil {
+		c.User = url.User(redacted)
+	}
+	q := c.Query()
+	changed := false
+	for k := range q {
+		if isSensitiveName(k) {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
+
+func isSensitiveName(name string) bool {
+	n := strings.ToLower(name)
+	n = strings.NewReplacer("-", "", "_", "").Replace(n)
+	switch n {
+	case "authorization", "proxyauthorization", "cookie", "setcookie", "key", "token":
+		return true
+	}
+	for _, s := range []string{"apikey", "accesstoken", "authtoken", "refreshtoken", "secret", "password"} {
+		if strings.Contains(n, s) {
+			return true
+		}
+	}
+	return false
+}
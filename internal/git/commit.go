@@ -2,33 +2,128 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
+	"strconv"
 	"strings"
 )
 
-// Commit creates a git commit with the given message.
-func Commit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	out, err := cmd.CombinedOutput()
+// CommitOptions adjusts how a commit is created.
+type CommitOptions struct {
+	// Amend replaces HEAD instead of creating a new commit.
+	Amend bool
+	// Sign signs the commit with the configured GPG or SSH key (-S).
+	Sign bool
+	// Signoff adds a Signed-off-by trailer (--signoff).
+	Signoff bool
+	// NoVerify skips the pre-commit and commit-msg hooks (--no-verify).
+	NoVerify bool
+	// Trailers are "Key: value" lines appended with --trailer.
+	Trailers []string
+}
+
+// CommitError is returned when git commit fails. Output holds everything
+// git and its hooks printed.
+type CommitError struct {
+	Amend  bool
+	Output string
+}
+
+func (e *CommitError) Error() string {
+	cmd := "git commit"
+	if e.Amend {
+		cmd = "git commit --amend"
+	}
+	return fmt.Sprintf("%s: %s", cmd, e.Output)
+}
+
+// CommitWith creates a commit with the given message and options. The
+// message is passed on stdin (git commit -F -) so multi-line messages are
+// kept as written; only surrounding whitespace is cleaned up.
+func (r *Repo) CommitWith(message string, opts CommitOptions) error {
+	args := []string{"commit", "-F", "-", "--cleanup=whitespace"}
+	if opts.Amend {
+		args = append(args, "--amend")
+	}
+	if opts.Sign {
+		args = append(args, "-S")
+	}
+	if opts.Signoff {
+		args = append(args, "--signoff")
+	}
+	if opts.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	for _, t := range opts.Trailers {
+		args = append(args, "--trailer", t)
+	}
+	// Trailers are placed by git interpret-trailers, which takes trailing
+	// lines starting with the comment character for comments and puts the
+	// trailers above them. Pick one the message does not use.
+	if opts.Signoff || len(opts.Trailers) > 0 {
+		if c := unusedCommentChar(message); c != "" {
+			args = append([]string{"-c", "core.commentChar=" + c}, args...)
+		}
+	}
+
+	cmd := r.command(args...)
+	cmd.Stdin = strings.NewReader(message)
+	out, err := runGit(cmd, cmd.CombinedOutput)
 	if err != nil {
-		return fmt.Errorf("git commit: %s", strings.TrimSpace(string(out)))
+		return &CommitError{Amend: opts.Amend, Output: strings.TrimSpace(string(out))}
 	}
 	return nil
 }
 
-// Push pushes the current branch to its upstream remote.
-func Push() error {
-	cmd := exec.Command("git", "push")
-	out, err := cmd.CombinedOutput()
+// unusedCommentChar returns a comment character that starts no line of
+// message, or "" when all candidates are taken.
+func unusedCommentChar(message string) string {
+	for _, c := range []string{"#", ";", "@", "!", "$", "%", "^", "&", "|", ":"} {
+		used := false
+		for _, line := range strings.Split(message, "\n") {
+			if strings.HasPrefix(line, c) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return c
+		}
+	}
+	return ""
+}
+
+// Commit creates a git commit with the given message.
+func (r *Repo) Commit(message string) error {
+	return r.CommitWith(message, CommitOptions{})
+}
+
+// Amend replaces the HEAD commit with the staged changes and message.
+func (r *Repo) Amend(message string) error {
+	return r.CommitWith(message, CommitOptions{Amend: true})
+}
+
+// HasHead reports whether the repository has at least one commit.
+func (r *Repo) HasHead() bool {
+	return r.run("rev-parse", "--verify", "--quiet", "HEAD^{commit}") == nil
+}
+
+// HeadMessage returns the full message of the HEAD commit.
+func (r *Repo) HeadMessage() (string, error) {
+	out, err := r.output("log", "-1", "--format=%B")
 	if err != nil {
-		return fmt.Errorf("git push: %s", strings.TrimSpace(string(out)))
+		return "", fmt.Errorf("git log: %w", err)
 	}
-	return nil
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HeadPushed reports whether HEAD is reachable from the current branch's
+// upstream. It returns false when there is no upstream.
+func (r *Repo) HeadPushed() bool {
+	return r.run("merge-base", "--is-ancestor", "HEAD", "@{upstream}") == nil
 }
 
 // CurrentBranch returns the name of the current branch.
-func CurrentBranch() (string, error) {
-	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+func (r *Repo) CurrentBranch() (string, error) {
+	out, err := r.output("rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", err
 	}
@@ -36,31 +131,55 @@ func CurrentBranch() (string, error) {
 }
 
 // Tag creates a git tag with the given version string.
-func Tag(version string) error {
-	cmd := exec.Command("git", "tag", version)
-	out, err := cmd.CombinedOutput()
+func (r *Repo) Tag(version string) error {
+	out, err := r.combinedOutput("tag", version)
 	if err != nil {
 		return fmt.Errorf("git tag: %s", strings.TrimSpace(string(out)))
 	}
 	return nil
 }
 
-// PushTag pushes a specific tag to the remote.
-func PushTag(tag string) error {
-	cmd := exec.Command("git", "push", "origin", tag)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git push tag: %s", strings.TrimSpace(string(out)))
-	}
-	return nil
-}
-
 // LatestTag returns the most recent tag reachable from HEAD.
 // Returns an empty string if no tags exist.
-func LatestTag() string {
-	out, err := exec.Command("git", "describe", "--tags", "--abbrev=0").Output()
+func (r *Repo) LatestTag() string {
+	out, err := r.output("describe", "--tags", "--abbrev=0")
 	if err != nil {
 		return ""
 	}
 	return strings.TrimSpace(string(out))
 }
+
+// RecentSubjects returns the subjects of the last n non-merge commits on HEAD,
+// newest first. It returns nil for a repository without commits.
+func (r *Repo) RecentSubjects(n int) ([]string, error) {
+	return r.RecentSubjectsExcept(n, nil)
+}
+
+// RecentSubjectsExcept is like RecentSubjects but skips the commits whose
+// full hashes are in except, e.g. the ones being reworded.
+func (r *Repo) RecentSubjectsExcept(n int, except []string) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	skip := make(map[string]bool, len(except))
+	for _, h := range except {
+		skip[h] = true
+	}
+	out, err := r.output("log", "--no-merges", "--format=%H %s", "-n", strconv.Itoa(n+len(except)))
+	if err != nil {
+		// HEAD does not exist yet (no commits).
+		return nil, nil
+	}
+	var subjects []string
+	for _, line := range strings.Split(string(out), "\n") {
+		hash, subject, _ := strings.Cut(line, " ")
+		if subject = strings.TrimSpace(subject); subject == "" || skip[hash] {
+			continue
+		}
+		subjects = append(subjects, subject)
+		if len(subjects) == n {
+			break
+		}
+	}
+	return subjects, nil
+}
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// headMessage returns the raw message of HEAD.
+func headMessage(t *testing.T, r *Repo) string {
+	t.Helper()
+	return gitRun(t, r, "log", "-1", "--format=%B")
+}
+
+func TestCommitWithTrailers(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	writeFile(t, r, "a.txt", "a\n")
+	gitRun(t, r, "add", "a.txt")
+
+	message := "feat(api): add endpoint\n\nFirst paragraph.\n\nSecond paragraph\nwith two lines."
+	err := r.CommitWith(message, CommitOptions{Trailers: []string{"Co-authored-by: Ada <ada@example.com>", "Refs: #12"}})
+	if err != nil {
+		t.Fatalf("CommitWith() error: %v", err)
+	}
+	want := message + "\n\nCo-authored-by: Ada <ada@example.com>\nRefs: #12"
+	if got := headMessage(t, r); got != want {
+		t.Fatalf("message =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCommitWithSignoff(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	writeFile(t, r, "a.txt", "a\n")
+	gitRun(t, r, "add", "a.txt")
+
+	if err := r.CommitWith("fix: handle nil config", CommitOptions{Signoff: true}); err != nil {
+		t.Fatalf("CommitWith() error: %v", err)
+	}
+	want := "fix: handle nil config\n\nSigned-off-by: Test <test@example.com>"
+	if got := headMessage(t, r); got != want {
+		t.Fatalf("message = %q, want %q", got, want)
+	}
+}
+
+func TestCommitWithKeepsHashLines(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	writeFile(t, r, "a.txt", "a\n")
+	gitRun(t, r, "add", "a.txt")
+
+	// Lines starting with "#" are part of the message, even at the end
+	// where git would otherwise treat them as comments.
+	message := "docs: describe setup\n\n# Setup\nRun make.\n# Notes"
+	if err := r.CommitWith(message, CommitOptions{Signoff: true, Trailers: []string{"Refs: 7"}}); err != nil {
+		t.Fatalf("CommitWith() error: %v", err)
+	}
+	want := message + "\n\nSigned-off-by: Test <test@example.com>\nRefs: 7"
+	if got := headMessage(t, r); got != want {
+		t.Fatalf("message =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCommitWithReportsHookOutput(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	writeFile(t, r, "a.txt", "a\n")
+	gitRun(t, r, "add", "a.txt")
+	hook := filepath.Join(r.Dir(), ".git", "hooks", "pre-commit")
+	if err := os.MkdirAll(filepath.Dir(hook), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\necho 'lint failed: a.txt' >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := r.CommitWith("feat: add a", CommitOptions{})
+	var commitErr *CommitError
+	if !errors.As(err, &commitErr) {
+		t.Fatalf("CommitWith() error = %v, want a CommitError", err)
+	}
+	if commitErr.Amend || !strings.Contains(commitErr.Output, "lint failed: a.txt") {
+		t.Fatalf("CommitError = %+v, want the hook output", commitErr)
+	}
+	if !strings.HasPrefix(err.Error(), "git commit: ") {
+		t.Fatalf("Error() = %q", err.Error())
+	}
+
+	// --no-verify skips the hook.
+	if err := r.CommitWith("feat: add a", CommitOptions{NoVerify: true}); err != nil {
+		t.Fatalf("CommitWith(NoVerify) error: %v", err)
+	}
+}
+
+func TestUnusedCommentChar(t *testing.T) {
+	t.Parallel()
+
+	if got := unusedCommentChar("feat: x\n\n# a\n; b"); got != "@" {
+		t.Fatalf("unusedCommentChar() = %q, want @", got)
+	}
+	if got := unusedCommentChar("feat: x"); got != "#" {
+		t.Fatalf("unusedCommentChar() = %q, want #", got)
+	}
+}
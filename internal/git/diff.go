@@ -2,13 +2,13 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
+	"os"
 	"strings"
 )
 
 // StagedDiff returns the diff of staged changes.
-func StagedDiff(maxLines int) (string, error) {
-	out, err := exec.Command("git", "diff", "--cached").Output()
+func (r *Repo) StagedDiff(maxLines int) (string, error) {
+	out, err := r.output("diff", "--cached")
 	if err != nil {
 		return "", fmt.Errorf("git diff --cached: %w", err)
 	}
@@ -16,11 +16,11 @@ func StagedDiff(maxLines int) (string, error) {
 }
 
 // AllDiff returns the diff of all changes (staged + unstaged).
-func AllDiff(maxLines int) (string, error) {
-	out, err := exec.Command("git", "diff", "HEAD").Output()
+func (r *Repo) AllDiff(maxLines int) (string, error) {
+	out, err := r.output("diff", "HEAD")
 	if err != nil {
 		// HEAD might not exist (initial commit), fall back to diff of staged
-		out2, err2 := exec.Command("git", "diff", "--cached").Output()
+		out2, err2 := r.output("diff", "--cached")
 		if err2 != nil {
 			return "", fmt.Errorf("git diff: %w", err)
 		}
@@ -29,9 +29,35 @@ func AllDiff(maxLines int) (string, error) {
 	return truncateDiff(string(out), maxLines), nil
 }
 
+// WorkingTreeDiff returns the diff of all changes including untracked files,
+// without touching the index.
+func (r *Repo) WorkingTreeDiff(maxLines int) (string, error) {
+	diff, err := r.AllDiff(0)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := r.output("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return "", fmt.Errorf("git ls-files: %w", err)
+	}
+	var b strings.Builder
+	b.WriteString(diff)
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name == "" {
+			continue
+		}
+		// --no-index exits 1 when the files differ, which is always the
+		// case here, so only the output matters.
+		fileDiff, _ := r.output("diff", "--no-index", "--", os.DevNull, name)
+		b.Write(fileDiff)
+	}
+	return truncateDiff(b.String(), maxLines), nil
+}
+
 // StagedFileNames returns a list of staged file names.
-func StagedFileNames() ([]string, error) {
-	out, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+func (r *Repo) StagedFileNames() ([]string, error) {
+	out, err := r.output("diff", "--cached", "--name-only")
 	if err != nil {
 		return nil, fmt.Errorf("git diff --cached --name-only: %w", err)
 	}
@@ -43,13 +69,13 @@ func StagedFileNames() ([]string, error) {
 }
 
 // StageAll runs git add -A to stage all changes.
-func StageAll() error {
-	return exec.Command("git", "add", "-A").Run()
+func (r *Repo) StageAll() error {
+	return r.run("add", "-A")
 }
 
 // DiffStat returns a short stat summary of the staged diff.
-func DiffStat() (string, error) {
-	out, err := exec.Command("git", "diff", "--cached", "--stat").Output()
+func (r *Repo) DiffStat() (string, error) {
+	out, err := r.output("diff", "--cached", "--stat")
 	if err != nil {
 		return "", err
 	}
@@ -68,3 +94,43 @@ func truncateDiff(diff string, maxLines int) string {
 	truncated += fmt.Sprintf("\n\n... (truncated %d lines)", len(lines)-maxLines)
 	return truncated
 }
+
+// amendBase returns the revision an amended HEAD is compared against: its
+// parent, or the empty tree when HEAD is the root commit.
+func (r *Repo) amendBase() (string, error) {
+	if err := r.run("rev-parse", "--verify", "--quiet", "HEAD~1^{commit}"); err == nil {
+		return "HEAD~1", nil
+	}
+	out, err := r.output("hash-object", "-t", "tree", os.DevNull)
+	if err != nil {
+		return "", fmt.Errorf("git hash-object: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// AmendDiff returns the diff of HEAD against its parent plus any staged
+// changes, i.e. what the commit will contain after "git commit --amend".
+func (r *Repo) AmendDiff(maxLines int) (string, error) {
+	base, err := r.amendBase()
+	if err != nil {
+		return "", err
+	}
+	out, err := r.output("diff", "--cached", base)
+	if err != nil {
+		return "", fmt.Errorf("git diff --cached %s: %w", base, err)
+	}
+	return truncateDiff(string(out), maxLines), nil
+}
+
+// AmendDiffStat returns a short stat summary of AmendDiff.
+func (r *Repo) AmendDiffStat() (string, error) {
+	base, err := r.amendBase()
+	if err != nil {
+		return "", err
+	}
+	out, err := r.output("diff", "--cached", "--stat", base)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
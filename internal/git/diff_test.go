@@ -0,0 +1,70 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAmendDiffRootCommit(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	commitAll(t, r, "a.txt", "first\n", "initial")
+	writeFile(t, r, "b.txt", "second\n")
+	gitRun(t, r, "add", "b.txt")
+
+	diff, err := r.AmendDiff(0)
+	if err != nil {
+		t.Fatalf("AmendDiff() on a root commit error: %v", err)
+	}
+	for _, want := range []string{
+		"diff --git a/a.txt b/a.txt\nnew file mode",
+		"+first",
+		"diff --git a/b.txt b/b.txt\nnew file mode",
+		"+second",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Fatalf("AmendDiff() missing %q against the empty tree:\n%s", want, diff)
+		}
+	}
+	if stat, err := r.AmendDiffStat(); err != nil || !strings.Contains(stat, "2 files changed") {
+		t.Fatalf("AmendDiffStat() = %q, %v; want 2 files changed", stat, err)
+	}
+}
+
+func TestAmendDiffAgainstParent(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	commitAll(t, r, "a.txt", "first\n", "initial")
+	commitAll(t, r, "a.txt", "first\nmore\n", "second")
+	writeFile(t, r, "b.txt", "staged\n")
+	gitRun(t, r, "add", "b.txt")
+
+	diff, err := r.AmendDiff(0)
+	if err != nil {
+		t.Fatalf("AmendDiff() error: %v", err)
+	}
+	if strings.Contains(diff, "+first") || !strings.Contains(diff, "+more") || !strings.Contains(diff, "+staged") {
+		t.Fatalf("AmendDiff() should cover HEAD and the staged file only:\n%s", diff)
+	}
+}
+
+func TestHeadPushed(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	commitAll(t, r, "a.txt", "a\n", "initial")
+	if r.HeadPushed() {
+		t.Fatalf("HeadPushed() without an upstream = true")
+	}
+
+	local, _ := syncTestRepos(t)
+	if !local.HeadPushed() {
+		t.Fatalf("HeadPushed() right after a push = false")
+	}
+	commitAll(t, local, "b.txt", "b\n", "local only")
+	if local.HeadPushed() {
+		t.Fatalf("HeadPushed() with an unpushed commit = true")
+	}
+}
@@ -0,0 +1,172 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FileStatus is one entry of "git status": a path with its index and
+// working tree status codes (see git-status(1), short format).
+type FileStatus struct {
+	Path string
+	// OrigPath is the source of a staged rename or copy.
+	OrigPath string
+	Index    byte
+	Worktree byte
+}
+
+// Untracked reports whether the file is not tracked by git.
+func (f FileStatus) Untracked() bool { return f.Index == '?' }
+
+// Staged reports whether the index differs from HEAD for the file.
+func (f FileStatus) Staged() bool { return f.Index != ' ' && f.Index != '?' }
+
+// Unstaged reports whether the working tree differs from the index.
+func (f FileStatus) Unstaged() bool { return f.Worktree != ' ' }
+
+// Deleted reports whether the file is deleted in the index or working tree.
+func (f FileStatus) Deleted() bool { return f.Index == 'D' || f.Worktree == 'D' }
+
+// StatusFiles lists changed and untracked files. Ignored files are omitted
+// and untracked directories are expanded to their files.
+func (r *Repo) StatusFiles() ([]FileStatus, error) {
+	out, err := r.output("status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	var files []FileStatus
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 4 {
+			continue
+		}
+		f := FileStatus{Index: e[0], Worktree: e[1], Path: e[3:]}
+		// Renames and copies are followed by their source path.
+		if (f.Index == 'R' || f.Index == 'C') && i+1 < len(entries) {
+			i++
+			f.OrigPath = entries[i]
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// FileStat counts the changed lines of one file.
+type FileStat struct {
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// FileStats returns per-file line counts of staged, unstaged and untracked
+// changes combined, keyed by path.
+func (r *Repo) FileStats(files []FileStatus) map[string]FileStat {
+	stats := make(map[string]FileStat)
+	for _, args := range [][]string{
+		{"diff", "--cached", "--numstat", "-z"},
+		{"diff", "--numstat", "-z"},
+	} {
+		out, err := r.output(args...)
+		if err != nil {
+			continue
+		}
+		addNumstat(stats, string(out))
+	}
+	for _, f := range files {
+		if !f.Untracked() {
+			continue
+		}
+		// --no-index exits 1 when the files differ.
+		out, _ := r.output("diff", "--no-index", "--numstat", "-z", "--", os.DevNull, f.Path)
+		one := make(map[string]FileStat)
+		addNumstat(one, string(out))
+		for _, s := range one {
+			stats[f.Path] = s
+		}
+	}
+	return stats
+}
+
+// addNumstat parses "git diff --numstat -z" output into stats.
+func addNumstat(stats map[string]FileStat, out string) {
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		path := parts[2]
+		// Renames have an empty path followed by the old and new paths.
+		if path == "" && i+2 < len(fields) {
+			path = fields[i+2]
+			i += 2
+		}
+		s := stats[path]
+		if parts[0] == "-" {
+			s.Binary = true
+		} else {
+			added, _ := strconv.Atoi(parts[0])
+			deleted, _ := strconv.Atoi(parts[1])
+			s.Added += added
+			s.Deleted += deleted
+		}
+		stats[path] = s
+	}
+}
+
+// StagePaths stages the given paths, including deletions.
+func (r *Repo) StagePaths(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	out, err := r.combinedOutput(append([]string{"add", "-A", "--"}, paths...)...)
+	if err != nil {
+		return fmt.Errorf("git add: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// UnstagePaths resets the given paths in the index to HEAD, keeping the
+// working tree. Before the first commit they are removed from the index;
+// paths that are not in it, such as untracked files, are left alone.
+func (r *Repo) UnstagePaths(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	args := append([]string{"reset", "-q", "--"}, paths...)
+	if !r.HasHead() {
+		args = append([]string{"rm", "--cached", "-r", "-q", "--ignore-unmatch", "--"}, paths...)
+	}
+	out, err := r.combinedOutput(args...)
+	if err != nil {
+		return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// StageTracked stages modifications and deletions of tracked files only.
+func (r *Repo) StageTracked() error {
+	return r.run("add", "-u")
+}
+
+// WriteTree saves the index as a tree object and returns its hash.
+func (r *Repo) WriteTree() (string, error) {
+	out, err := r.output("write-tree")
+	if err != nil {
+		return "", fmt.Errorf("git write-tree: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ReadTree replaces the index with tree, e.g. one saved by WriteTree. The
+// working tree is not touched.
+func (r *Repo) ReadTree(tree string) error {
+	out, err := r.combinedOutput("read-tree", tree)
+	if err != nil {
+		return fmt.Errorf("git read-tree: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
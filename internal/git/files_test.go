@@ -0,0 +1,85 @@
+package git
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestOpenRootFromSubdirectory(t *testing.T) {
+	t.Parallel()
+
+	base := newTestRepo(t)
+	writeFile(t, base, "top.txt", "one\n")
+	writeFile(t, base, "sub/inner.txt", "one\n")
+	gitRun(t, base, "add", "-A")
+	gitRun(t, base, "commit", "-q", "-m", "initial")
+
+	writeFile(t, base, "top.txt", "one\ntwo\n")
+	writeFile(t, base, "sub/inner.txt", "one\ntwo\nthree\n")
+	writeFile(t, base, "sub/new.txt", "fresh\n")
+
+	r, err := OpenRoot(filepath.Join(base.Dir(), "sub"))
+	if err != nil {
+		t.Fatalf("OpenRoot() error: %v", err)
+	}
+	if got, want := r.Dir(), gitRun(t, base, "rev-parse", "--show-toplevel"); got != want {
+		t.Fatalf("OpenRoot() dir = %q, want %q", got, want)
+	}
+
+	files, err := r.StatusFiles()
+	if err != nil {
+		t.Fatalf("StatusFiles() error: %v", err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	slices.Sort(paths)
+	if want := []string{"sub/inner.txt", "sub/new.txt", "top.txt"}; !slices.Equal(paths, want) {
+		t.Fatalf("StatusFiles() paths = %v, want %v", paths, want)
+	}
+
+	stats := r.FileStats(files)
+	if s := stats["sub/new.txt"]; s.Added != 1 {
+		t.Errorf("FileStats(sub/new.txt) = %+v, want 1 added line", s)
+	}
+	if s := stats["sub/inner.txt"]; s.Added != 2 {
+		t.Errorf("FileStats(sub/inner.txt) = %+v, want 2 added lines", s)
+	}
+
+	d, err := r.FileHunks("sub/inner.txt", false)
+	if err != nil || len(d.Hunks) != 1 {
+		t.Fatalf("FileHunks(sub/inner.txt) = %d hunks, %v; want 1 hunk", len(d.Hunks), err)
+	}
+	d, err = r.FileHunks("sub/new.txt", true)
+	if err != nil || len(d.Hunks) != 1 {
+		t.Fatalf("FileHunks(sub/new.txt) = %d hunks, %v; want 1 hunk", len(d.Hunks), err)
+	}
+
+	if err := r.StagePaths(paths); err != nil {
+		t.Fatalf("StagePaths() error: %v", err)
+	}
+	if got := gitRun(t, base, "diff", "--cached", "--name-only"); got != "sub/inner.txt\nsub/new.txt\ntop.txt" {
+		t.Fatalf("staged files = %q", got)
+	}
+	d, err = r.StagedFileHunks("sub/inner.txt")
+	if err != nil || len(d.Hunks) != 1 {
+		t.Fatalf("StagedFileHunks(sub/inner.txt) = %d hunks, %v; want 1 hunk", len(d.Hunks), err)
+	}
+
+	if err := r.UnstagePaths([]string{"sub/inner.txt", "sub/new.txt"}); err != nil {
+		t.Fatalf("UnstagePaths() error: %v", err)
+	}
+	if got := gitRun(t, base, "diff", "--cached", "--name-only"); got != "top.txt" {
+		t.Fatalf("staged files after unstage = %q, want top.txt", got)
+	}
+}
+
+func TestOpenRootOutsideRepository(t *testing.T) {
+	t.Parallel()
+
+	if _, err := OpenRoot(t.TempDir()); err == nil {
+		t.Fatalf("OpenRoot() accepted a directory outside any repository")
+	}
+}
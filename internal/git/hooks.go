@@ -0,0 +1,21 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// HooksDir returns the absolute path of the repository's hooks directory,
+// honoring core.hooksPath.
+func (r *Repo) HooksDir() (string, error) {
+	out, err := r.output("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		// The path is relative to the directory git ran in.
+		dir = filepath.Join(r.dir, dir)
+	}
+	return filepath.Abs(dir)
+}
@@ -0,0 +1,178 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Hunk is one "@@" section of a unified diff.
+type Hunk struct {
+	// Header is the "@@ -a,b +c,d @@" line.
+	Header string
+	// Lines are the context, added and removed lines with their prefix.
+	Lines []string
+}
+
+// Changes returns the added and removed lines of the hunk, which identify
+// it independently of its position and surrounding context.
+func (h Hunk) Changes() string {
+	var b strings.Builder
+	for _, l := range h.Lines {
+		if strings.HasPrefix(l, "+") || strings.HasPrefix(l, "-") {
+			b.WriteString(l)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// FileDiff is the diff of a single file split into hunks.
+type FileDiff struct {
+	// Path is the file's path (the new path for renames).
+	Path string
+	// Raw is the unparsed diff of the file. It is applied as a whole when
+	// the file has no hunks, e.g. binary files and mode changes.
+	Raw string
+	// Header holds the lines before the first hunk ("diff --git", mode and
+	// "---"/"+++" lines).
+	Header []string
+	Hunks  []Hunk
+}
+
+// ParseFileDiff splits the unified diff of one file into its header and hunks.
+func ParseFileDiff(diff string) FileDiff {
+	d := FileDiff{Raw: diff}
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			d.Hunks = append(d.Hunks, Hunk{Header: l})
+		case len(d.Hunks) > 0:
+			h := &d.Hunks[len(d.Hunks)-1]
+			h.Lines = append(h.Lines, l)
+		case l != "":
+			d.Header = append(d.Header, l)
+			if idx := strings.LastIndex(l, " b/"); strings.HasPrefix(l, "diff --git ") && idx >= 0 {
+				d.Path = l[idx+3:]
+			}
+		}
+	}
+	return d
+}
+
+// Patch returns a patch with the header and the hunks whose selected entry
+// is true, or "" when none are selected.
+func (d FileDiff) Patch(selected []bool) string {
+	var b strings.Builder
+	for i, h := range d.Hunks {
+		if i >= len(selected) || !selected[i] {
+			continue
+		}
+		b.WriteString(h.Header)
+		b.WriteByte('\n')
+		for _, l := range h.Lines {
+			b.WriteString(l)
+			b.WriteByte('\n')
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return strings.Join(d.Header, "\n") + "\n" + b.String()
+}
+
+// FileHunks returns the full change of a file (HEAD against the working
+// tree) as hunks. New files, including those in a repository without
+// commits, are diffed against an empty file.
+func (r *Repo) FileHunks(path string, isNew bool) (FileDiff, error) {
+	var out []byte
+	var err error
+	if isNew || !r.HasHead() {
+		// --no-index exits 1 when the files differ.
+		out, _ = r.output("diff", "--no-index", "--no-color", "--no-ext-diff", "--", os.DevNull, path)
+	} else {
+		out, err = r.output("diff", "--no-color", "--no-ext-diff", "HEAD", "--", path)
+		if err != nil {
+			return FileDiff{}, fmt.Errorf("git diff HEAD -- %s: %w", path, err)
+		}
+	}
+	return ParseFileDiff(string(out)), nil
+}
+
+// StagedFileHunks returns the staged change of a file as hunks.
+func (r *Repo) StagedFileHunks(path string) (FileDiff, error) {
+	out, err := r.output("diff", "--cached", "--no-color", "--no-ext-diff", "--", path)
+	if err != nil {
+		return FileDiff{}, fmt.Errorf("git diff --cached -- %s: %w", path, err)
+	}
+	return ParseFileDiff(string(out)), nil
+}
+
+// StagedFileDiffs returns the staged changes split per file, including
+// binary data. Renames are reported as a deletion and an addition so each
+// side can be applied on its own.
+func (r *Repo) StagedFileDiffs() ([]FileDiff, error) {
+	out, err := r.output("diff", "--cached", "--no-renames", "--binary", "--no-color", "--no-ext-diff")
+	if err != nil {
+		return nil, fmt.Errorf("git diff --cached: %w", err)
+	}
+	var diffs []FileDiff
+	text := string(out)
+	for len(text) > 0 {
+		next := strings.Index(text[1:], "\ndiff --git ")
+		if next < 0 {
+			diffs = append(diffs, ParseFileDiff(text))
+			break
+		}
+		diffs = append(diffs, ParseFileDiff(text[:next+2]))
+		text = text[next+2:]
+	}
+	return diffs, nil
+}
+
+// PatchUnit identifies one hunk of a FileDiff, or the whole file when Hunk
+// is -1.
+type PatchUnit struct {
+	File int
+	Hunk int
+}
+
+// BuildPatch joins units of diffs into a single patch, keeping the file and
+// hunk order of diffs.
+func BuildPatch(diffs []FileDiff, units []PatchUnit) string {
+	whole := make(map[int]bool)
+	selected := make(map[int][]bool)
+	for _, u := range units {
+		if u.Hunk < 0 {
+			whole[u.File] = true
+			continue
+		}
+		if selected[u.File] == nil {
+			selected[u.File] = make([]bool, len(diffs[u.File].Hunks))
+		}
+		selected[u.File][u.Hunk] = true
+	}
+
+	var b strings.Builder
+	for i, d := range diffs {
+		switch {
+		case whole[i]:
+			b.WriteString(d.Raw)
+		case selected[i] != nil:
+			b.WriteString(d.Patch(selected[i]))
+		}
+	}
+	return b.String()
+}
+
+// ApplyCached applies a patch to the index only (git apply --cached).
+func (r *Repo) ApplyCached(patch string) error {
+	cmd := r.command("apply", "--cached", "--recount", "-")
+	cmd.Stdin = strings.NewReader(patch)
+	out, err := runGit(cmd, cmd.CombinedOutput)
+	if err != nil {
+		return fmt.Errorf("git apply --cached: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns "line 1\n" through "line n\n" with the lines in
+// replace swapped for their values.
+func numberedLines(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if s, ok := replace[i]; ok {
+			b.WriteString(s + "\n")
+			continue
+		}
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+// hunkTestRepo commits a long file and a file to delete, then changes the
+// long file in two distant places, deletes the other and adds a new file.
+func hunkTestRepo(t *testing.T) *Repo {
+	t.Helper()
+	r := newTestRepo(t)
+	writeFile(t, r, "long.txt", numberedLines(30, nil))
+	writeFile(t, r, "gone.txt", "bye\n")
+	gitRun(t, r, "add", "-A")
+	gitRun(t, r, "commit", "-q", "-m", "initial")
+
+	writeFile(t, r, "long.txt", numberedLines(30, map[int]string{2: "first change", 28: "second change"}))
+	if err := os.Remove(filepath.Join(r.Dir(), "gone.txt")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	writeFile(t, r, "new.txt", "hello\nworld\n")
+	return r
+}
+
+func TestApplyCachedSelectedHunks(t *testing.T) {
+	t.Parallel()
+
+	r := hunkTestRepo(t)
+
+	// The hunk browser resets the file in the index and applies the
+	// selected hunks, see tui.applyHunks.
+	stage := func(path string, isNew bool, on func(n int) []bool) {
+		t.Helper()
+		d, err := r.FileHunks(path, isNew)
+		if err != nil {
+			t.Fatalf("FileHunks(%s) error: %v", path, err)
+		}
+		if d.Path != path {
+			t.Fatalf("FileHunks(%s) path = %q", path, d.Path)
+		}
+		if err := r.UnstagePaths([]string{path}); err != nil {
+			t.Fatalf("UnstagePaths(%s) error: %v", path, err)
+		}
+		if patch := d.Patch(on(len(d.Hunks))); patch != "" {
+			if err := r.ApplyCached(patch); err != nil {
+				t.Fatalf("ApplyCached(%s) error: %v", path, err)
+			}
+		}
+	}
+
+	stage("long.txt", false, func(n int) []bool {
+		if n != 2 {
+			t.Fatalf("long.txt has %d hunks, want 2", n)
+		}
+		return []bool{false, true}
+	})
+	stage("new.txt", true, func(n int) []bool { return []bool{true} })
+	stage("gone.txt", false, func(n int) []bool { return []bool{true} })
+
+	if got, want := gitRun(t, r, "diff", "--cached", "--name-status"), "D\tgone.txt\nM\tlong.txt\nA\tnew.txt"; got != want {
+		t.Fatalf("staged files = %q, want %q", got, want)
+	}
+	if got, want := gitRun(t, r, "show", ":long.txt")+"\n", numberedLines(30, map[int]string{28: "second change"}); got != want {
+		t.Fatalf("staged long.txt =\n%s\nwant\n%s", got, want)
+	}
+	if got := gitRun(t, r, "show", ":new.txt"); got != "hello\nworld" {
+		t.Fatalf("staged new.txt = %q", got)
+	}
+	// The unselected hunk stays in the working tree.
+	if got := gitRun(t, r, "diff", "--name-only"); got != "long.txt" {
+		t.Fatalf("unstaged files = %q, want long.txt", got)
+	}
+
+	// Deselecting everything leaves the file unstaged.
+	stage("long.txt", false, func(n int) []bool { return make([]bool, n) })
+	if got := gitRun(t, r, "diff", "--cached", "--name-only"); got != "gone.txt\nnew.txt" {
+		t.Fatalf("staged files after deselect = %q", got)
+	}
+}
+
+func TestBuildPatchRestagesUnits(t *testing.T) {
+	t.Parallel()
+
+	r := hunkTestRepo(t)
+	gitRun(t, r, "add", "-A")
+
+	diffs, err := r.StagedFileDiffs()
+	if err != nil {
+		t.Fatalf("StagedFileDiffs() error: %v", err)
+	}
+	files := make(map[string]int)
+	for i, d := range diffs {
+		files[d.Path] = i
+	}
+	long, ok := files["long.txt"]
+	if !ok || len(diffs[long].Hunks) != 2 {
+		t.Fatalf("StagedFileDiffs() = %+v, want long.txt with 2 hunks", files)
+	}
+
+	// Split the staged changes the way the split flow commits one group:
+	// reset the index, then apply a subset of the units.
+	patch := BuildPatch(diffs, []PatchUnit{
+		{File: files["new.txt"], Hunk: -1},
+		{File: long, Hunk: 0},
+		{File: files["gone.txt"], Hunk: -1},
+	})
+	gitRun(t, r, "reset", "-q")
+	if err := r.ApplyCached(patch); err != nil {
+		t.Fatalf("ApplyCached() error: %v\n%s", err, patch)
+	}
+
+	if got, want := gitRun(t, r, "diff", "--cached", "--name-status"), "D\tgone.txt\nM\tlong.txt\nA\tnew.txt"; got != want {
+		t.Fatalf("staged files = %q, want %q", got, want)
+	}
+	if got, want := gitRun(t, r, "show", ":long.txt")+"\n", numberedLines(30, map[int]string{2: "first change"}); got != want {
+		t.Fatalf("staged long.txt =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReadTreeRestagesUncommittedGroups(t *testing.T) {
+	t.Parallel()
+
+	r := hunkTestRepo(t)
+	gitRun(t, r, "add", "-A")
+	saved, err := r.WriteTree()
+	if err != nil {
+		t.Fatalf("WriteTree() error: %v", err)
+	}
+
+	// Commit the first group of a split, then restore the index as split
+	// does when a later group fails.
+	diffs, err := r.StagedFileDiffs()
+	if err != nil {
+		t.Fatalf("StagedFileDiffs() error: %v", err)
+	}
+	var first []PatchUnit
+	for i, d := range diffs {
+		if d.Path == "new.txt" {
+			first = append(first, PatchUnit{File: i, Hunk: -1})
+		}
+	}
+	gitRun(t, r, "reset", "-q")
+	if err := r.ApplyCached(BuildPatch(diffs, first)); err != nil {
+		t.Fatalf("ApplyCached() error: %v", err)
+	}
+	gitRun(t, r, "commit", "-q", "-m", "add new.txt")
+
+	if err := r.ReadTree(saved); err != nil {
+		t.Fatalf("ReadTree() error: %v", err)
+	}
+	if got, want := gitRun(t, r, "diff", "--cached", "--name-status"), "D\tgone.txt\nM\tlong.txt"; got != want {
+		t.Fatalf("staged files = %q, want %q", got, want)
+	}
+	if got := gitRun(t, r, "status", "--porcelain"); got != "D  gone.txt\nM  long.txt" {
+		t.Fatalf("status after restore = %q", got)
+	}
+}
+
+func TestApplyCachedNewFileWithoutHead(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	writeFile(t, r, "n.txt", numberedLines(30, nil))
+
+	// Same steps as the hunk browser for an untracked file before the
+	// first commit.
+	d, err := r.FileHunks("n.txt", true)
+	if err != nil || len(d.Hunks) != 1 {
+		t.Fatalf("FileHunks(n.txt) = %d hunks, %v; want 1 hunk", len(d.Hunks), err)
+	}
+	if err := r.UnstagePaths([]string{"n.txt"}); err != nil {
+		t.Fatalf("UnstagePaths() on an untracked file error: %v", err)
+	}
+	if err := r.ApplyCached(d.Patch([]bool{true})); err != nil {
+		t.Fatalf("ApplyCached() error: %v", err)
+	}
+	if got := gitRun(t, r, "status", "--porcelain"); got != "A  n.txt" {
+		t.Fatalf("status = %q, want n.txt staged", got)
+	}
+
+	// Unstaging it again removes it from the index.
+	if err := r.UnstagePaths([]string{"n.txt"}); err != nil {
+		t.Fatalf("UnstagePaths() error: %v", err)
+	}
+	if got := gitRun(t, r, "status", "--porcelain"); got != "?? n.txt" {
+		t.Fatalf("status after unstage = %q, want n.txt untracked", got)
+	}
+}
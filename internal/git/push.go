@@ -0,0 +1,159 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PushTarget describes where the current branch is pushed.
+type PushTarget struct {
+	Branch string
+	// Upstream is the branch's upstream, e.g. "origin/main", or "" when
+	// none is configured.
+	Upstream string
+	// UpstreamRemote is the remote of Upstream.
+	UpstreamRemote string
+	Remotes        []string
+}
+
+// CurrentPushTarget reads the current branch, its upstream and the
+// configured remotes.
+func (r *Repo) CurrentPushTarget() (PushTarget, error) {
+	var t PushTarget
+	branch, err := r.CurrentBranch()
+	if err != nil {
+		return t, fmt.Errorf("git rev-parse: %w", err)
+	}
+	t.Branch = branch
+
+	if out, err := r.output("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+		t.Upstream = strings.TrimSpace(string(out))
+		if out, err := r.output("config", "--get", "branch."+branch+".remote"); err == nil {
+			t.UpstreamRemote = strings.TrimSpace(string(out))
+		}
+	}
+
+	out, err := r.output("remote")
+	if err != nil {
+		return t, fmt.Errorf("git remote: %w", err)
+	}
+	t.Remotes = strings.Fields(string(out))
+	return t, nil
+}
+
+// DefaultRemote picks the remote to push a branch without upstream to:
+// preferred when it exists, else "origin", else the first remote. It
+// returns "" when there are no remotes.
+func (t PushTarget) DefaultRemote(preferred string) string {
+	switch {
+	case preferred != "" && slices.Contains(t.Remotes, preferred):
+		return preferred
+	case slices.Contains(t.Remotes, "origin"):
+		return "origin"
+	case len(t.Remotes) > 0:
+		return t.Remotes[0]
+	}
+	return ""
+}
+
+// PushOptions adjusts git push.
+type PushOptions struct {
+	// Remote and Branch name the destination explicitly; empty pushes to
+	// the upstream.
+	Remote string
+	Branch string
+	// SetUpstream records Remote/Branch as the upstream (-u).
+	SetUpstream bool
+	// ForceWithLease allows replacing the remote branch as long as it is
+	// still where it was last fetched, e.g. after an amend.
+	ForceWithLease bool
+	// Options are sent to the server with -o, e.g. "ci.skip".
+	Options []string
+}
+
+// PushWith pushes the current branch. progress, if not nil, receives each
+// progress line git prints while the push runs.
+func (r *Repo) PushWith(opts PushOptions, progress func(line string)) error {
+	args := []string{"push", "--progress"}
+	if opts.SetUpstream {
+		args = append(args, "-u")
+	}
+	if opts.ForceWithLease {
+		args = append(args, "--force-with-lease")
+	}
+	for _, o := range opts.Options {
+		args = append(args, "-o", o)
+	}
+	if opts.Remote != "" {
+		args = append(args, opts.Remote)
+		if opts.Branch != "" {
+			args = append(args, opts.Branch)
+		}
+	}
+
+	cmd := r.command(args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("git push: %w", err)
+	}
+	_, err = runGit(cmd, func() (struct{}, error) {
+		if err := cmd.Start(); err != nil {
+			return struct{}{}, err
+		}
+		scanner := bufio.NewScanner(stderr)
+		scanner.Split(scanProgressLines)
+		for scanner.Scan() {
+			line := scanner.Text()
+			// Lines ending in \r are updated in place; keep only the
+			// final state of each in the output.
+			text := strings.TrimRight(line, "\r\n")
+			if strings.HasSuffix(line, "\n") {
+				out.WriteString(text + "\n")
+			}
+			if progress != nil && text != "" {
+				progress(text)
+			}
+		}
+		return struct{}{}, cmd.Wait()
+	})
+	if err != nil {
+		return fmt.Errorf("git push: %s", strings.TrimSpace(out.String()))
+	}
+	return nil
+}
+
+// scanProgressLines is a bufio.SplitFunc that splits on \n and \r and keeps
+// the terminator in the token.
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Push pushes the current branch to its upstream remote.
+func (r *Repo) Push() error {
+	return r.PushWith(PushOptions{}, nil)
+}
+
+// PushTag pushes a specific tag to origin.
+func (r *Repo) PushTag(tag string) error {
+	return r.PushTagTo("origin", tag)
+}
+
+// PushTagTo pushes a specific tag to remote.
+func (r *Repo) PushTagTo(remote, tag string) error {
+	out, err := r.combinedOutput("push", remote, tag)
+	if err != nil {
+		return fmt.Errorf("git push tag: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package git
+
+import "strings"
+
+// RepoRoot returns the absolute path of the working tree's top-level directory.
+func (r *Repo) RepoRoot() (string, error) {
+	out, err := r.output("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RemoteURLs returns the fetch URLs of all configured remotes.
+func (r *Repo) RemoteURLs() []string {
+	out, err := r.output("config", "--get-regexp", `^remote\..*\.url$`)
+	if err != nil {
+		// Exit status 1 means no remotes are configured.
+		return nil
+	}
+	var urls []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if _, url, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
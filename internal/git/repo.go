@@ -0,0 +1,111 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Repo is a git working tree. Every command runs in its directory.
+type Repo struct {
+	dir string
+}
+
+// Open returns a Repo for dir. An empty dir means the process working
+// directory. The directory is not checked; use IsGitRepo for that.
+func Open(dir string) *Repo {
+	return &Repo{dir: dir}
+}
+
+// OpenRoot returns a Repo for the top-level directory of the working tree
+// containing dir (empty for the process working directory). Paths reported
+// by status are relative to that directory, so commands that take them back
+// must run there rather than in a subdirectory.
+func OpenRoot(dir string) (*Repo, error) {
+	root, err := Open(dir).RepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	return Open(root), nil
+}
+
+// Dir returns the directory the repository was opened with.
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Submodule is a checked-out submodule of a repository.
+type Submodule struct {
+	// Path is relative to the superproject's top-level directory.
+	Path string
+	Repo *Repo
+}
+
+// DirtySubmodules lists the submodules with uncommitted changes or
+// untracked files of their own. Submodules whose only change is a new
+// checked-out commit are left out; the superproject commits those.
+func (r *Repo) DirtySubmodules() ([]Submodule, error) {
+	root, err := r.RepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse --show-toplevel: %w", err)
+	}
+	out, err := r.output("status", "--porcelain=v2", "-z", "--ignore-submodules=none")
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	var subs []Submodule
+	for _, path := range parseDirtySubmodules(string(out)) {
+		subs = append(subs, Submodule{Path: path, Repo: Open(filepath.Join(root, path))})
+	}
+	return subs, nil
+}
+
+// parseDirtySubmodules returns the paths of the submodules with tracked
+// changes or untracked files in "git status --porcelain=v2 -z" output.
+func parseDirtySubmodules(out string) []string {
+	var paths []string
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		fields := strings.Fields(entries[i])
+		if len(fields) < 3 {
+			continue
+		}
+		var path string
+		switch fields[0] {
+		case "1":
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			path = fieldsTail(entries[i], 8)
+		case "2":
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <score> <path>, then
+			// the original path as its own entry.
+			path = fieldsTail(entries[i], 9)
+			i++
+		case "u":
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			path = fieldsTail(entries[i], 10)
+		default:
+			continue
+		}
+		// <sub> is "S<c><m><u>" for submodules: commit changed, tracked
+		// changes, untracked files.
+		sub := fields[2]
+		if len(sub) != 4 || sub[0] != 'S' || (sub[2] != 'M' && sub[3] != 'U') {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// fieldsTail returns what follows the first n space-separated fields of
+// entry, so paths containing spaces are kept intact.
+func fieldsTail(entry string, n int) string {
+	for ; n > 0; n-- {
+		_, rest, ok := strings.Cut(entry, " ")
+		if !ok {
+			return ""
+		}
+		entry = rest
+	}
+	return entry
+}
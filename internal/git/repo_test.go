@@ -0,0 +1,44 @@
+package git
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDirtySubmodules(t *testing.T) {
+	t.Parallel()
+
+	const h = "0123456789012345678901234567890123456789"
+	entries := []string{
+		// Tracked changes inside the submodule.
+		"1 .M S.M. 160000 160000 160000 " + h + " " + h + " libs/tracked",
+		// Only untracked files inside the submodule, with a space in the path.
+		"1 .M S..U 160000 160000 160000 " + h + " " + h + " libs/with space",
+		// Only a new checked-out commit: the superproject commits it.
+		"1 .M SC.. 160000 160000 160000 " + h + " " + h + " libs/moved",
+		// A regular file.
+		"1 .M N... 100644 100644 100644 " + h + " " + h + " main.go",
+		// A renamed submodule with changes; the original path follows.
+		"2 R. S.M. 160000 160000 160000 " + h + " " + h + " R100 libs/renamed",
+		"libs/old name",
+		// A conflicted submodule with untracked files.
+		"u UU S..U 160000 160000 160000 160000 " + h + " " + h + " " + h + " libs/conflict",
+		"? untracked.txt",
+		"",
+	}
+
+	got := parseDirtySubmodules(strings.Join(entries, "\x00"))
+	want := []string{"libs/tracked", "libs/with space", "libs/renamed", "libs/conflict"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("parseDirtySubmodules() = %q, want %q", got, want)
+	}
+}
+
+func TestParseDirtySubmodulesEmpty(t *testing.T) {
+	t.Parallel()
+
+	if got := parseDirtySubmodules(""); len(got) != 0 {
+		t.Fatalf("parseDirtySubmodules(\"\") = %q, want none", got)
+	}
+}
@@ -0,0 +1,171 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LogCommit is a commit read by RangeCommits.
+type LogCommit struct {
+	Hash    string
+	Parents []string
+	// AuthorName, AuthorEmail and AuthorDate ("<unix> <tz>") are preserved
+	// when the commit is rewritten.
+	AuthorName  string
+	AuthorEmail string
+	AuthorDate  string
+	Message     string
+	// Signed reports whether the commit carries a GPG or SSH signature,
+	// which a rewrite cannot keep.
+	Signed bool
+}
+
+// Subject returns the first line of the commit message.
+func (c LogCommit) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// ShortHash returns the abbreviated commit hash.
+func (c LogCommit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// RangeCommits lists the commits of a revision range, oldest first. A single
+// revision such as "HEAD~3" means "HEAD~3..HEAD".
+func (r *Repo) RangeCommits(revRange string) ([]LogCommit, error) {
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+	return r.logCommits(revRange)
+}
+
+// CommitChain lists the commits from base (exclusive) to HEAD, oldest first.
+// An empty base means the whole history of HEAD.
+func (r *Repo) CommitChain(base string) ([]LogCommit, error) {
+	if base == "" {
+		return r.logCommits("HEAD")
+	}
+	return r.logCommits(base + "..HEAD")
+}
+
+// logCommits lists the commits selected by rev, oldest first.
+func (r *Repo) logCommits(rev string) ([]LogCommit, error) {
+	out, err := r.combinedOutput("log", "--reverse", "--topo-order", "--date=raw",
+		"--format=%H%x00%P%x00%an%x00%ae%x00%ad%x00%G?%x00%B%x1e", rev, "--")
+	if err != nil {
+		return nil, fmt.Errorf("git log %s: %s", rev, strings.TrimSpace(string(out)))
+	}
+	return parseLogCommits(string(out)), nil
+}
+
+func parseLogCommits(out string) []LogCommit {
+	var commits []LogCommit
+	for _, record := range strings.Split(out, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		fields := strings.SplitN(record, "\x00", 7)
+		if len(fields) < 7 {
+			continue
+		}
+		commits = append(commits, LogCommit{
+			Hash:        fields[0],
+			Parents:     strings.Fields(fields[1]),
+			AuthorName:  fields[2],
+			AuthorEmail: fields[3],
+			AuthorDate:  fields[4],
+			Signed:      fields[5] != "N",
+			Message:     strings.TrimSpace(fields[6]),
+		})
+	}
+	return commits
+}
+
+// CommitDiff returns the patch introduced by a commit.
+func (r *Repo) CommitDiff(hash string, maxLines int) (string, error) {
+	out, err := r.output("show", "--format=", "--patch", hash)
+	if err != nil {
+		return "", fmt.Errorf("git show %s: %w", hash, err)
+	}
+	return truncateDiff(string(out), maxLines), nil
+}
+
+// IsPushed reports whether the commit is reachable from the current
+// branch's upstream. It returns false when there is no upstream.
+func (r *Repo) IsPushed(hash string) bool {
+	return r.run("merge-base", "--is-ancestor", hash, "@{upstream}") == nil
+}
+
+// RewriteMessages recreates chain, a linear run of commits ending at HEAD,
+// with the messages in newMessages (keyed by hash; missing entries keep their
+// message) and moves HEAD to the result. Trees and authors are kept, so the
+// working tree and index are unaffected. Signatures are not: with sign set the
+// new commits are signed with the configured key (-S), otherwise they are
+// unsigned. It returns the new HEAD hash.
+func (r *Repo) RewriteMessages(chain []LogCommit, newMessages map[string]string, sign bool) (string, error) {
+	if len(chain) == 0 {
+		return "", fmt.Errorf("no commits to rewrite")
+	}
+	head, err := r.output("rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse HEAD: %w", err)
+	}
+	oldHead := strings.TrimSpace(string(head))
+	if chain[len(chain)-1].Hash != oldHead {
+		return "", fmt.Errorf("commits must end at HEAD")
+	}
+
+	parent := ""
+	if ps := chain[0].Parents; len(ps) > 0 {
+		parent = ps[0]
+	}
+	for i, c := range chain {
+		if len(c.Parents) > 1 {
+			return "", fmt.Errorf("cannot rewrite merge commit %s", c.ShortHash())
+		}
+		if i > 0 && (len(c.Parents) == 0 || c.Parents[0] != chain[i-1].Hash) {
+			return "", fmt.Errorf("history between %s and HEAD is not linear", chain[0].ShortHash())
+		}
+
+		message := c.Message
+		if m, ok := newMessages[c.Hash]; ok {
+			message = m
+		}
+		parent, err = r.commitTree(c, parent, message, sign)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	out, err := r.combinedOutput("update-ref", "-m", "firecommit: reword", "HEAD", parent, oldHead)
+	if err != nil {
+		return "", fmt.Errorf("git update-ref: %s", strings.TrimSpace(string(out)))
+	}
+	return parent, nil
+}
+
+// commitTree creates a copy of c with a new parent and message.
+func (r *Repo) commitTree(c LogCommit, parent, message string, sign bool) (string, error) {
+	args := []string{"commit-tree", c.Hash + "^{tree}"}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	if sign {
+		args = append(args, "-S")
+	}
+	cmd := r.command(args...)
+	cmd.Stdin = strings.NewReader(message + "\n")
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+c.AuthorName,
+		"GIT_AUTHOR_EMAIL="+c.AuthorEmail,
+		"GIT_AUTHOR_DATE="+c.AuthorDate,
+	)
+	out, err := runGit(cmd, cmd.Output)
+	if err != nil {
+		return "", fmt.Errorf("git commit-tree for %s: %w", c.ShortHash(), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
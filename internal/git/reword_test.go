@@ -0,0 +1,169 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates an empty repository with a local identity and
+// signing disabled, so tests do not depend on the user's git config.
+func newTestRepo(t *testing.T) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := Open(t.TempDir())
+	gitRun(t, r, "init", "-q")
+	gitRun(t, r, "config", "user.name", "Test")
+	gitRun(t, r, "config", "user.email", "test@example.com")
+	gitRun(t, r, "config", "commit.gpgsign", "false")
+	return r
+}
+
+// gitRun runs git in r and returns its trimmed stdout.
+func gitRun(t *testing.T, r *Repo, args ...string) string {
+	t.Helper()
+	out, err := r.combinedOutput(args...)
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writeFile writes content to name, relative to the repository root.
+func writeFile(t *testing.T, r *Repo, name, content string) {
+	t.Helper()
+	path := filepath.Join(r.Dir(), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestRewriteMessagesKeepsTreesAndAuthors(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	authors := []string{"Ada <ada@example.com>", "Grace <grace@example.com>", "Linus <linus@example.com>"}
+	for i, author := range authors {
+		writeFile(t, r, "file.txt", strings.Repeat("line\n", i+1))
+		gitRun(t, r, "add", "file.txt")
+		gitRun(t, r, "commit", "-q", "-m", "commit "+string(rune('a'+i)),
+			"--author", author, "--date", "2024-01-0"+string(rune('1'+i))+"T10:00:00+02:00")
+	}
+
+	ranged, err := r.RangeCommits("HEAD~1")
+	if err != nil {
+		t.Fatalf("RangeCommits() error: %v", err)
+	}
+	if len(ranged) != 1 || ranged[0].Message != "commit c" {
+		t.Fatalf("RangeCommits(HEAD~1) = %+v, want only the last commit", ranged)
+	}
+
+	chain, err := r.CommitChain("")
+	if err != nil {
+		t.Fatalf("CommitChain() error: %v", err)
+	}
+	if len(chain) != 3 || len(chain[0].Parents) != 0 {
+		t.Fatalf("CommitChain(\"\") = %d commits, want the whole history from the root", len(chain))
+	}
+	for _, c := range chain {
+		if c.Signed {
+			t.Fatalf("commit %s reported as signed", c.ShortHash())
+		}
+	}
+
+	newHead, err := r.RewriteMessages(chain, map[string]string{
+		chain[0].Hash: "feat: first",
+		chain[2].Hash: "fix: third\n\nWith a body.",
+	}, false)
+	if err != nil {
+		t.Fatalf("RewriteMessages() error: %v", err)
+	}
+	if got := gitRun(t, r, "rev-parse", "HEAD"); got != newHead {
+		t.Fatalf("HEAD = %s, want %s", got, newHead)
+	}
+
+	rewritten, err := r.CommitChain("")
+	if err != nil {
+		t.Fatalf("CommitChain() after rewrite error: %v", err)
+	}
+	if len(rewritten) != len(chain) {
+		t.Fatalf("rewritten chain has %d commits, want %d", len(rewritten), len(chain))
+	}
+	wantMessages := []string{"feat: first", "commit b", "fix: third\n\nWith a body."}
+	for i, c := range rewritten {
+		old := chain[i]
+		if c.Hash == old.Hash {
+			t.Fatalf("commit %d was not rewritten", i)
+		}
+		if c.Message != wantMessages[i] {
+			t.Errorf("commit %d message = %q, want %q", i, c.Message, wantMessages[i])
+		}
+		if c.AuthorName != old.AuthorName || c.AuthorEmail != old.AuthorEmail || c.AuthorDate != old.AuthorDate {
+			t.Errorf("commit %d author = %s <%s> %s, want %s <%s> %s", i,
+				c.AuthorName, c.AuthorEmail, c.AuthorDate, old.AuthorName, old.AuthorEmail, old.AuthorDate)
+		}
+		if got, want := gitRun(t, r, "rev-parse", c.Hash+"^{tree}"), gitRun(t, r, "rev-parse", old.Hash+"^{tree}"); got != want {
+			t.Errorf("commit %d tree = %s, want %s", i, got, want)
+		}
+	}
+
+	if got := gitRun(t, r, "reflog", "-1", "--format=%gs", "HEAD"); got != "firecommit: reword" {
+		t.Fatalf("reflog entry = %q, want %q", got, "firecommit: reword")
+	}
+	if status := gitRun(t, r, "status", "--porcelain"); status != "" {
+		t.Fatalf("working tree changed by rewrite:\n%s", status)
+	}
+}
+
+func TestRewriteMessagesRejectsStaleChain(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	writeFile(t, r, "a.txt", "a\n")
+	gitRun(t, r, "add", "a.txt")
+	gitRun(t, r, "commit", "-q", "-m", "first")
+	chain, err := r.CommitChain("")
+	if err != nil {
+		t.Fatalf("CommitChain() error: %v", err)
+	}
+	writeFile(t, r, "b.txt", "b\n")
+	gitRun(t, r, "add", "b.txt")
+	gitRun(t, r, "commit", "-q", "-m", "second")
+
+	if _, err := r.RewriteMessages(chain, nil, false); err == nil {
+		t.Fatalf("RewriteMessages() accepted a chain that no longer ends at HEAD")
+	}
+}
+
+func TestRecentSubjectsExcept(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRepo(t)
+	for _, s := range []string{"one", "two", "three", "four"} {
+		writeFile(t, r, "file.txt", s+"\n")
+		gitRun(t, r, "add", "file.txt")
+		gitRun(t, r, "commit", "-q", "-m", s)
+	}
+	chain, err := r.RangeCommits("HEAD~2")
+	if err != nil || len(chain) != 2 {
+		t.Fatalf("RangeCommits(HEAD~2) = %d commits, %v", len(chain), err)
+	}
+
+	got, err := r.RecentSubjectsExcept(2, []string{chain[0].Hash, chain[1].Hash})
+	if err != nil {
+		t.Fatalf("RecentSubjectsExcept() error: %v", err)
+	}
+	if strings.Join(got, ",") != "two,one" {
+		t.Fatalf("RecentSubjectsExcept() = %q, want the two commits before the range", got)
+	}
+	if got, _ := r.RecentSubjects(2); strings.Join(got, ",") != "four,three" {
+		t.Fatalf("RecentSubjects() = %q", got)
+	}
+}
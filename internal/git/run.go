@@ -0,0 +1,67 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lieyanc/fire-commit/internal/debuglog"
+)
+
+// command builds an exec.Cmd for git with the given arguments, running in
+// the repository's directory.
+func (r *Repo) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	return cmd
+}
+
+// runGit runs cmd via fn (Run, Output or CombinedOutput) and records the
+// invocation, exit code and duration in the debug log.
+func runGit[T any](cmd *exec.Cmd, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	res, err := fn()
+	if debuglog.Enabled() {
+		exitCode := 0
+		if err != nil {
+			exitCode = -1
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				exitCode = exitErr.ExitCode()
+			}
+		}
+		fields := map[string]any{
+			"args":        strings.Join(cmd.Args[1:], " "),
+			"exit_code":   exitCode,
+			"duration_ms": time.Since(start).Milliseconds(),
+		}
+		if cmd.Dir != "" {
+			fields["dir"] = cmd.Dir
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		debuglog.Log("git", fields)
+	}
+	return res, err
+}
+
+// run executes git and waits for it to finish.
+func (r *Repo) run(args ...string) error {
+	cmd := r.command(args...)
+	_, err := runGit(cmd, func() (struct{}, error) { return struct{}{}, cmd.Run() })
+	return err
+}
+
+// output executes git and returns its stdout.
+func (r *Repo) output(args ...string) ([]byte, error) {
+	cmd := r.command(args...)
+	return runGit(cmd, cmd.Output)
+}
+
+// combinedOutput executes git and returns its stdout and stderr.
+func (r *Repo) combinedOutput(args ...string) ([]byte, error) {
+	cmd := r.command(args...)
+	return runGit(cmd, cmd.CombinedOutput)
+}
@@ -6,9 +6,8 @@ import (
 )
 
 // HasStagedChanges returns true if there are staged changes in the index.
-func HasStagedChanges() (bool, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--quiet")
-	err := cmd.Run()
+func (r *Repo) HasStagedChanges() (bool, error) {
+	err := r.run("diff", "--cached", "--quiet")
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			// Exit code 1 means there are differences
@@ -22,9 +21,8 @@ func HasStagedChanges() (bool, error) {
 }
 
 // HasUnstagedChanges returns true if there are unstaged changes in the working tree.
-func HasUnstagedChanges() (bool, error) {
-	cmd := exec.Command("git", "diff", "--quiet")
-	err := cmd.Run()
+func (r *Repo) HasUnstagedChanges() (bool, error) {
+	err := r.run("diff", "--quiet")
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if exitErr.ExitCode() == 1 {
@@ -37,16 +35,17 @@ func HasUnstagedChanges() (bool, error) {
 }
 
 // HasUntrackedFiles returns true if there are untracked files.
-func HasUntrackedFiles() (bool, error) {
-	out, err := exec.Command("git", "ls-files", "--others", "--exclude-standard").Output()
+func (r *Repo) HasUntrackedFiles() (bool, error) {
+	out, err := r.output("ls-files", "--others", "--exclude-standard")
 	if err != nil {
 		return false, err
 	}
 	return strings.TrimSpace(string(out)) != "", nil
 }
 
-// IsGitRepo returns true if the current directory is inside a git repository.
-func IsGitRepo() bool {
-	err := exec.Command("git", "rev-parse", "--is-inside-work-tree").Run()
+// IsGitRepo returns true if the repository's directory is inside a git
+// working tree.
+func (r *Repo) IsGitRepo() bool {
+	err := r.run("rev-parse", "--is-inside-work-tree")
 	return err == nil
 }
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConflictError reports a rebase or merge that stopped on conflicts. The
+// operation has been aborted, so the branch is as it was before.
+type ConflictError struct {
+	// Op is "rebase" or "merge".
+	Op       string
+	Upstream string
+	Files    []string
+}
+
+func (e *ConflictError) Error() string {
+	what := "rebase onto " + e.Upstream
+	if e.Op == "merge" {
+		what = "merge of " + e.Upstream
+	}
+	return fmt.Sprintf("%s stopped on conflicts in %s; it was aborted and the branch is unchanged",
+		what, strings.Join(e.Files, ", "))
+}
+
+// FetchUpstream fetches the remote of the current branch.
+func (r *Repo) FetchUpstream() error {
+	out, err := r.combinedOutput("fetch", "--quiet")
+	if err != nil {
+		return fmt.Errorf("git fetch: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// UpstreamDivergence returns how many commits HEAD has that its upstream
+// does not (ahead) and the other way round (behind).
+func (r *Repo) UpstreamDivergence() (ahead, behind int, err error) {
+	out, err := r.output("rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+	if err != nil {
+		return 0, 0, fmt.Errorf("git rev-list: %w", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("git rev-list: unexpected output %q", strings.TrimSpace(string(out)))
+	}
+	ahead, _ = strconv.Atoi(fields[0])
+	behind, _ = strconv.Atoi(fields[1])
+	return ahead, behind, nil
+}
+
+// RebaseOntoUpstream replays the local commits on top of the upstream.
+// Uncommitted changes are stashed and restored around it.
+func (r *Repo) RebaseOntoUpstream(upstream string) error {
+	return r.integrate("rebase", upstream, "rebase", "--autostash", "@{upstream}")
+}
+
+// MergeUpstream merges the upstream into the current branch.
+func (r *Repo) MergeUpstream(upstream string) error {
+	return r.integrate("merge", upstream, "merge", "--autostash", "--no-edit", "@{upstream}")
+}
+
+// integrate runs a rebase or merge and aborts it when it stops, returning a
+// ConflictError that lists the conflicting files.
+func (r *Repo) integrate(op, upstream string, args ...string) error {
+	out, err := r.combinedOutput(args...)
+	if err == nil {
+		return nil
+	}
+	files := r.conflictedFiles()
+	// Harmless when the operation never started.
+	_ = r.run(op, "--abort")
+	if len(files) > 0 {
+		return &ConflictError{Op: op, Upstream: upstream, Files: files}
+	}
+	return fmt.Errorf("git %s: %s", op, strings.TrimSpace(string(out)))
+}
+
+// conflictedFiles lists the paths with unresolved conflicts.
+func (r *Repo) conflictedFiles() []string {
+	out, err := r.output("diff", "--name-only", "--diff-filter=U", "-z")
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
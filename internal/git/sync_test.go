@@ -0,0 +1,146 @@
+package git
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// syncTestRepos returns a repository and a second clone of the same bare
+// remote, both on main with one shared commit and main tracking origin/main.
+func syncTestRepos(t *testing.T) (local, other *Repo) {
+	t.Helper()
+	local = newTestRepo(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	gitRun(t, local, "init", "-q", "--bare", remote)
+	gitRun(t, Open(remote), "symbolic-ref", "HEAD", "refs/heads/main")
+
+	gitRun(t, local, "symbolic-ref", "HEAD", "refs/heads/main")
+	writeFile(t, local, "shared.txt", "one\n")
+	gitRun(t, local, "add", "-A")
+	gitRun(t, local, "commit", "-q", "-m", "initial")
+	gitRun(t, local, "remote", "add", "origin", remote)
+	gitRun(t, local, "push", "-q", "-u", "origin", "main")
+
+	dir := filepath.Join(t.TempDir(), "other")
+	gitRun(t, local, "clone", "-q", remote, dir)
+	other = Open(dir)
+	gitRun(t, other, "config", "user.name", "Other")
+	gitRun(t, other, "config", "user.email", "other@example.com")
+	gitRun(t, other, "config", "commit.gpgsign", "false")
+	return local, other
+}
+
+// commitAll writes content to name and commits everything in r.
+func commitAll(t *testing.T, r *Repo, name, content, message string) {
+	t.Helper()
+	writeFile(t, r, name, content)
+	gitRun(t, r, "add", "-A")
+	gitRun(t, r, "commit", "-q", "-m", message)
+}
+
+func TestUpstreamDivergence(t *testing.T) {
+	t.Parallel()
+
+	local, other := syncTestRepos(t)
+	commitAll(t, other, "theirs.txt", "a\n", "theirs 1")
+	commitAll(t, other, "theirs.txt", "b\n", "theirs 2")
+	gitRun(t, other, "push", "-q")
+	commitAll(t, local, "mine.txt", "a\n", "mine")
+
+	ahead, behind, err := local.UpstreamDivergence()
+	if err != nil || ahead != 1 || behind != 0 {
+		t.Fatalf("UpstreamDivergence() before fetch = %d, %d, %v; want 1, 0", ahead, behind, err)
+	}
+	if err := local.FetchUpstream(); err != nil {
+		t.Fatalf("FetchUpstream() error: %v", err)
+	}
+	ahead, behind, err = local.UpstreamDivergence()
+	if err != nil || ahead != 1 || behind != 2 {
+		t.Fatalf("UpstreamDivergence() after fetch = %d, %d, %v; want 1, 2", ahead, behind, err)
+	}
+}
+
+func TestIntegrateUpstreamClean(t *testing.T) {
+	t.Parallel()
+
+	for _, op := range []string{"rebase", "merge"} {
+		t.Run(op, func(t *testing.T) {
+			t.Parallel()
+
+			local, other := syncTestRepos(t)
+			commitAll(t, other, "theirs.txt", "theirs\n", "theirs")
+			gitRun(t, other, "push", "-q")
+			commitAll(t, local, "mine.txt", "mine\n", "mine")
+			// Uncommitted changes survive thanks to --autostash.
+			writeFile(t, local, "shared.txt", "one\nwip\n")
+			if err := local.FetchUpstream(); err != nil {
+				t.Fatalf("FetchUpstream() error: %v", err)
+			}
+
+			var err error
+			if op == "rebase" {
+				err = local.RebaseOntoUpstream("origin/main")
+			} else {
+				err = local.MergeUpstream("origin/main")
+			}
+			if err != nil {
+				t.Fatalf("%s error: %v", op, err)
+			}
+
+			_, behind, err := local.UpstreamDivergence()
+			if err != nil || behind != 0 {
+				t.Fatalf("behind = %d, %v after %s; want 0", behind, err, op)
+			}
+			if op == "rebase" && gitRun(t, local, "rev-list", "--merges", "--count", "HEAD") != "0" {
+				t.Fatalf("rebase created a merge commit")
+			}
+			if op == "merge" && gitRun(t, local, "rev-list", "--merges", "--count", "HEAD") != "1" {
+				t.Fatalf("merge did not create a merge commit")
+			}
+			if got := gitRun(t, local, "status", "--porcelain"); got != "M shared.txt" {
+				t.Fatalf("status = %q, want the stashed change restored", got)
+			}
+		})
+	}
+}
+
+func TestIntegrateUpstreamConflictAborts(t *testing.T) {
+	t.Parallel()
+
+	for _, op := range []string{"rebase", "merge"} {
+		t.Run(op, func(t *testing.T) {
+			t.Parallel()
+
+			local, other := syncTestRepos(t)
+			commitAll(t, other, "shared.txt", "theirs\n", "theirs")
+			gitRun(t, other, "push", "-q")
+			commitAll(t, local, "shared.txt", "mine\n", "mine")
+			if err := local.FetchUpstream(); err != nil {
+				t.Fatalf("FetchUpstream() error: %v", err)
+			}
+			head := gitRun(t, local, "rev-parse", "HEAD")
+
+			var err error
+			if op == "rebase" {
+				err = local.RebaseOntoUpstream("origin/main")
+			} else {
+				err = local.MergeUpstream("origin/main")
+			}
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("%s error = %v, want a ConflictError", op, err)
+			}
+			if conflict.Op != op || conflict.Upstream != "origin/main" || !slices.Equal(conflict.Files, []string{"shared.txt"}) {
+				t.Fatalf("ConflictError = %+v", conflict)
+			}
+			if got := gitRun(t, local, "rev-parse", "HEAD"); got != head {
+				t.Fatalf("HEAD moved to %s, want %s", got, head)
+			}
+			if got := gitRun(t, local, "status", "--porcelain"); got != "" {
+				t.Fatalf("working tree not clean after abort: %q", got)
+			}
+		})
+	}
+}
@@ -14,38 +14,76 @@ type AnthropicProvider struct {
 }
 
 // NewAnthropicProvider creates a provider for the Anthropic API.
-func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
-	client := anthropic.NewClient(option.WithAPIKey(apiKey))
+// Extra options (HTTP client, headers) are applied after the API key.
+func NewAnthropicProvider(apiKey, model string, opts ...option.RequestOption) *AnthropicProvider {
+	client := anthropic.NewClient(append([]option.RequestOption{option.WithAPIKey(apiKey)}, opts...)...)
 	return &AnthropicProvider{client: &client, model: model}
 }
 
 func (p *AnthropicProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
 	ch := make(chan StreamChunk, 64)
 
-	stream := p.client.Messages.NewStreaming(ctx, anthropic.MessageNewParams{
+	// The system prompt and the stable user prompt prefix are identical across
+	// parallel requests and runs, so both end in a cache breakpoint; only the
+	// diff block after them is billed at the full input rate on a cache hit.
+	prefixBlock := anthropic.TextBlockParam{
+		Text:         buildUserPromptPrefix(opts),
+		CacheControl: anthropic.NewCacheControlEphemeralParam(),
+	}
+	params := anthropic.MessageNewParams{
 		MaxTokens: 1024,
 		Model:     anthropic.Model(p.model),
 		System: []anthropic.TextBlockParam{
-			{Text: buildSystemPrompt(opts.Language)},
+			{
+				Text:         systemPromptFor(opts),
+				CacheControl: anthropic.NewCacheControlEphemeralParam(),
+			},
 		},
-		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(
-				anthropic.NewTextBlock(buildUserPrompt(diff)),
-			),
-		},
-	})
+		Messages: anthropicMessages(diff, prefixBlock, opts),
+	}
+	if opts.Structured {
+		// Forcing a single tool call makes the model emit the suggestion as
+		// tool input JSON, which streams as InputJSONDelta events.
+		params.Tools = []anthropic.ToolUnionParam{{
+			OfTool: &anthropic.ToolParam{
+				Name:        suggestionToolName,
+				Description: anthropic.String("Record the proposed commit message."),
+				InputSchema: anthropic.ToolInputSchemaParam{
+					Properties: suggestionSchemaProperties,
+					Required:   suggestionSchemaRequired,
+				},
+			},
+		}}
+		params.ToolChoice = anthropic.ToolChoiceParamOfTool(suggestionToolName)
+	}
+
+	stream := p.client.Messages.NewStreaming(ctx, params)
 
 	go func() {
 		defer close(ch)
+		var usage Usage
 		for stream.Next() {
 			event := stream.Current()
 			switch variant := event.AsAny().(type) {
+			case anthropic.MessageStartEvent:
+				u := variant.Message.Usage
+				usage.InputTokens = u.InputTokens
+				usage.CacheCreationTokens = u.CacheCreationInputTokens
+				usage.CacheReadTokens = u.CacheReadInputTokens
+				usage.OutputTokens = u.OutputTokens
+			case anthropic.MessageDeltaEvent:
+				// Delta usage is cumulative for the message.
+				usage.OutputTokens = variant.Usage.OutputTokens
 			case anthropic.ContentBlockDeltaEvent:
 				switch delta := variant.Delta.AsAny().(type) {
 				case anthropic.TextDelta:
 					if delta.Text != "" {
 						ch <- StreamChunk{Content: delta.Text}
 					}
+				case anthropic.InputJSONDelta:
+					if delta.PartialJSON != "" {
+						ch <- StreamChunk{Content: delta.PartialJSON}
+					}
 				}
 			}
 		}
@@ -53,8 +91,39 @@ func (p *AnthropicProvider) GenerateCommitMessages(ctx context.Context, diff str
 			ch <- StreamChunk{Err: err}
 			return
 		}
-		ch <- StreamChunk{Done: true}
+		ch <- StreamChunk{Done: true, Usage: &usage}
 	}()
 
 	return ch, nil
 }
+
+func (p *AnthropicProvider) ListModels(ctx context.Context) ([]string, error) {
+	var ids []string
+	iter := p.client.Models.ListAutoPaging(ctx, anthropic.ModelListParams{})
+	for iter.Next() {
+		ids = append(ids, iter.Current().ID)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// anthropicMessages builds the diff prompt, after the cached prefix block, and
+// any follow-up turns from opts.Messages.
+func anthropicMessages(diff string, prefix anthropic.TextBlockParam, opts GenerateOptions) []anthropic.MessageParam {
+	messages := []anthropic.MessageParam{
+		anthropic.NewUserMessage(
+			anthropic.ContentBlockParamUnion{OfText: &prefix},
+			anthropic.NewTextBlock(diff),
+		),
+	}
+	for _, m := range opts.Messages {
+		if m.Role == RoleAssistant {
+			messages = append(messages, anthropic.NewAssistantMessage(anthropic.NewTextBlock(m.Content)))
+		} else {
+			messages = append(messages, anthropic.NewUserMessage(anthropic.NewTextBlock(m.Content)))
+		}
+	}
+	return messages
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/lieyanc/fire-commit/internal/debuglog"
+)
+
+// debugMiddleware records each provider HTTP exchange in the debug log.
+// Request bodies carry the prompts; credentials in headers are redacted.
+func debugMiddleware(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	fields := map[string]any{
+		"method":  req.Method,
+		"url":     debuglog.RedactURL(req.URL),
+		"headers": debuglog.RedactHeaders(req.Header),
+	}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		fields["body"] = string(body)
+	}
+	debuglog.Log("llm.request", fields)
+
+	start := time.Now()
+	resp, err := next(req)
+	result := map[string]any{
+		"url":         debuglog.RedactURL(req.URL),
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result["error"] = err.Error()
+	} else {
+		result["status"] = resp.StatusCode
+		result["headers"] = debuglog.RedactHeaders(resp.Header)
+	}
+	debuglog.Log("llm.http_response", result)
+	return resp, err
+}
+
+// logStreamResult records the full streamed text of one generation slot.
+func logStreamResult(index int, raw, parsed string, start time.Time, err error) {
+	if !debuglog.Enabled() {
+		return
+	}
+	fields := map[string]any{
+		"index":       index,
+		"raw":         raw,
+		"parsed":      parsed,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	debuglog.Log("llm.response", fields)
+}
+
+// logUsage records token accounting, including prompt cache hits.
+func logUsage(index int, usage *Usage) {
+	if usage == nil || !debuglog.Enabled() {
+		return
+	}
+	debuglog.Log("llm.usage", map[string]any{
+		"index":                 index,
+		"input_tokens":          usage.InputTokens,
+		"output_tokens":         usage.OutputTokens,
+		"cache_creation_tokens": usage.CacheCreationTokens,
+		"cache_read_tokens":     usage.CacheReadTokens,
+	})
+}
@@ -0,0 +1,187 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// execWaitDelay is how long a cancelled command's output may stay open, e.g.
+// held by a background child, before it is closed.
+var execWaitDelay = 2 * time.Second
+
+// ExecProvider implements Provider by running an external command. The
+// command receives an ExecRequest as JSON on stdin and streams ExecResponse
+// objects as JSON lines on stdout.
+type ExecProvider struct {
+	command string
+	model   string
+	apiKey  string
+}
+
+// ExecRequest is written to the command's stdin.
+type ExecRequest struct {
+	System  string             `json:"system"`
+	Prompt  string             `json:"prompt"`
+	Diff    string             `json:"diff"`
+	Model   string             `json:"model,omitempty"`
+	Options ExecRequestOptions `json:"options"`
+	// Messages continue the conversation after Prompt (see
+	// GenerateOptions.Messages).
+	Messages []Message `json:"messages,omitempty"`
+}
+
+// ExecRequestOptions mirrors GenerateOptions for external commands.
+type ExecRequestOptions struct {
+	Language   string   `json:"language"`
+	Structured bool     `json:"structured"`
+	History    []string `json:"history,omitempty"`
+	// Split is set when the prompt asks for a commit plan (see
+	// GenerateOptions).
+	Split bool `json:"split,omitempty"`
+	// PreviousMessage is set when amending (see GenerateOptions).
+	PreviousMessage string `json:"previous_message,omitempty"`
+}
+
+// ExecResponse is one line of the command's stdout. Exactly one field is
+// expected to be set per line.
+type ExecResponse struct {
+	Content string `json:"content,omitempty"`
+	Done    bool   `json:"done,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ExecAPIKeyEnv is set in the command's environment when the provider has an
+// API key configured.
+const ExecAPIKeyEnv = "FIRECOMMIT_API_KEY"
+
+// NewExecProvider creates a provider that runs command through the shell.
+// model and apiKey are optional and passed through to the command.
+func NewExecProvider(command, model, apiKey string) *ExecProvider {
+	return &ExecProvider{command: command, model: model, apiKey: apiKey}
+}
+
+func (p *ExecProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
+	req, err := json.Marshal(ExecRequest{
+		System:   systemPromptFor(opts),
+		Prompt:   buildUserPromptFor(diff, opts),
+		Diff:     diff,
+		Model:    p.model,
+		Messages: opts.Messages,
+		Options: ExecRequestOptions{
+			Language:        opts.Language,
+			Structured:      opts.Structured,
+			History:         opts.History,
+			Split:           opts.Split,
+			PreviousMessage: opts.PreviousMessage,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", p.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.command)
+	}
+	cmd.Stdin = bytes.NewReader(req)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if p.apiKey != "" {
+		cmd.Env = append(os.Environ(), ExecAPIKeyEnv+"="+p.apiKey)
+	}
+	// Stdout goes through an in-process pipe that is closed once Wait
+	// returns. After a cancel, WaitDelay bounds how long Wait waits for
+	// grandchildren that still hold stdout or stderr open.
+	stdout, stdoutW := io.Pipe()
+	cmd.Stdout = stdoutW
+	cmd.WaitDelay = execWaitDelay
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start exec provider: %w", err)
+	}
+	waited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		stdoutW.Close()
+		waited <- err
+	}()
+
+	ch := make(chan StreamChunk, 64)
+	go func() {
+		defer close(ch)
+
+		streamErr := readExecStream(stdout, ch)
+		// Drain so the command never blocks on a full pipe after an error.
+		_, _ = io.Copy(io.Discard, stdout)
+		waitErr := <-waited
+		if errors.Is(waitErr, exec.ErrWaitDelay) {
+			// The command exited cleanly; only a leftover child kept
+			// its output open.
+			waitErr = nil
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			ch <- StreamChunk{Err: ctx.Err()}
+		case streamErr != nil:
+			ch <- StreamChunk{Err: streamErr}
+		case waitErr != nil:
+			ch <- StreamChunk{Err: execError(waitErr, stderr.String())}
+		default:
+			ch <- StreamChunk{Done: true}
+		}
+	}()
+
+	return ch, nil
+}
+
+// readExecStream forwards content lines to ch until the command reports done,
+// reports an error or closes stdout.
+func readExecStream(stdout io.Reader, ch chan<- StreamChunk) error {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var resp ExecResponse
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			return fmt.Errorf("exec provider: invalid output line %q", truncate(line, 80))
+		}
+		switch {
+		case resp.Error != "":
+			return errors.New("exec provider: " + resp.Error)
+		case resp.Content != "":
+			ch <- StreamChunk{Content: resp.Content}
+		case resp.Done:
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+func execError(err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("exec provider: %s", truncate(msg, 200))
+	}
+	return fmt.Errorf("exec provider: %w", err)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,166 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeStub writes an executable shell script and returns its path.
+func writeStub(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell stubs need sh")
+	}
+	path := filepath.Join(t.TempDir(), "stub.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func collectStream(t *testing.T, p Provider, opts GenerateOptions) (string, error) {
+	t.Helper()
+	ch, err := p.GenerateCommitMessages(context.Background(), "diff --git a/x b/x", opts)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessages() error = %v", err)
+	}
+	var b strings.Builder
+	var streamErr error
+	for chunk := range ch {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+		}
+		b.WriteString(chunk.Content)
+	}
+	return b.String(), streamErr
+}
+
+func TestExecProviderStreamsContent(t *testing.T) {
+	dir := t.TempDir()
+	reqFile := filepath.Join(dir, "req.json")
+	stub := writeStub(t, `cat > "$1"
+echo '{"content":"feat: add "}'
+echo '{"content":"exec provider"}'
+echo '{"done":true}'
+`)
+
+	p := NewExecProvider(stub+" "+reqFile, "gateway-large", "secret")
+	got, err := collectStream(t, p, GenerateOptions{Language: "en", Structured: true})
+	if err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+	if got != "feat: add exec provider" {
+		t.Fatalf("content = %q", got)
+	}
+
+	data, err := os.ReadFile(reqFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req ExecRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatalf("request is not JSON: %v", err)
+	}
+	if req.Diff != "diff --git a/x b/x" || req.Model != "gateway-large" || !req.Options.Structured {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if req.System == "" || !strings.Contains(req.Prompt, req.Diff) {
+		t.Fatalf("request is missing prompts: %+v", req)
+	}
+}
+
+func TestExecProviderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"error line", `echo '{"error":"quota exceeded"}'`, "quota exceeded"},
+		{"invalid line", `echo 'not json'`, "invalid output line"},
+		{"exit status", "echo 'gateway down' >&2\nexit 3", "gateway down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewExecProvider(writeStub(t, "cat >/dev/null\n"+tt.script), "", "")
+			_, err := collectStream(t, p, GenerateOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecProviderPassesAPIKey(t *testing.T) {
+	stub := writeStub(t, `cat >/dev/null
+printf '{"content":"%s"}\n' "$FIRECOMMIT_API_KEY"
+`)
+	got, err := collectStream(t, NewExecProvider(stub, "", "k-123"), GenerateOptions{})
+	if err != nil || got != "k-123" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+}
+
+func TestExecProviderSendsRefinementMessages(t *testing.T) {
+	reqFile := filepath.Join(t.TempDir(), "req.json")
+	stub := writeStub(t, `cat > "$1"
+echo '{"content":"feat(http): add endpoint"}'
+`)
+	opts := GenerateOptions{Messages: RefinementMessages("feat(api): add endpoint", "scope is http")}
+	if _, err := collectStream(t, NewExecProvider(stub+" "+reqFile, "", ""), opts); err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	data, err := os.ReadFile(reqFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req ExecRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Messages) != 2 || req.Messages[0].Role != RoleAssistant || !strings.Contains(req.Messages[1].Content, "scope is http") {
+		t.Fatalf("messages = %+v", req.Messages)
+	}
+}
+
+func TestExecProviderCancelWithBackgroundChild(t *testing.T) {
+	// The background sleep inherits stdout and outlives the killed shell.
+	stub := writeStub(t, `sleep 5 &
+echo '{"content":"feat: start"}'
+sleep 30
+`)
+	p := NewExecProvider(stub, "", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := p.GenerateCommitMessages(ctx, "diff --git a/x b/x", GenerateOptions{})
+	if err != nil {
+		t.Fatalf("GenerateCommitMessages() error = %v", err)
+	}
+	if chunk := <-ch; chunk.Content != "feat: start" {
+		t.Fatalf("first chunk = %+v", chunk)
+	}
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		var last error
+		for chunk := range ch {
+			last = chunk.Err
+		}
+		done <- last
+	}()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("final error = %v, want context.Canceled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("stream did not close after cancel")
+	}
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"syscall"
+
+	"github.com/lieyanc/fire-commit/internal/debuglog"
+)
+
+// FallbackProvider streams from Primary and switches to Fallback when the
+// primary provider cannot be reached before producing any output. Chunks
+// from Fallback are marked Offline so callers can tell the user.
+type FallbackProvider struct {
+	Primary  Provider
+	Fallback Provider
+}
+
+// NewFallbackProvider wraps primary so network failures fall back to fallback.
+func NewFallbackProvider(primary, fallback Provider) *FallbackProvider {
+	return &FallbackProvider{Primary: primary, Fallback: fallback}
+}
+
+func (p *FallbackProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
+	primary, err := p.Primary.GenerateCommitMessages(ctx, diff, opts)
+	if err != nil {
+		if !isUnreachable(ctx, err) {
+			return nil, err
+		}
+		logFallback(err)
+		fallback, err := p.Fallback.GenerateCommitMessages(ctx, diff, opts)
+		if err != nil {
+			return nil, err
+		}
+		return markOffline(fallback), nil
+	}
+
+	ch := make(chan StreamChunk, 64)
+	go func() {
+		defer close(ch)
+		started := false
+		for chunk := range primary {
+			if chunk.Err != nil && !started && isUnreachable(ctx, chunk.Err) {
+				logFallback(chunk.Err)
+				fallback, err := p.Fallback.GenerateCommitMessages(ctx, diff, opts)
+				if err != nil {
+					ch <- StreamChunk{Err: err}
+					return
+				}
+				for fc := range fallback {
+					fc.Offline = true
+					ch <- fc
+				}
+				return
+			}
+			if chunk.Content != "" {
+				started = true
+			}
+			ch <- chunk
+		}
+	}()
+	return ch, nil
+}
+
+// markOffline forwards ch with every chunk marked Offline.
+func markOffline(ch <-chan StreamChunk) <-chan StreamChunk {
+	out := make(chan StreamChunk, 64)
+	go func() {
+		defer close(out)
+		for chunk := range ch {
+			chunk.Offline = true
+			out <- chunk
+		}
+	}()
+	return out
+}
+
+// ListModels delegates to the primary provider.
+func (p *FallbackProvider) ListModels(ctx context.Context) ([]string, error) {
+	return ListModels(ctx, p.Primary)
+}
+
+// isUnreachable reports whether err means the provider could not be contacted
+// (DNS, refused connection, dial timeout) as opposed to an API-level error or
+// a user cancellation.
+func isUnreachable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return true
+	}
+	return false
+}
+
+func logFallback(err error) {
+	debuglog.Log("llm.fallback", map[string]any{"reason": err.Error()})
+}
@@ -0,0 +1,373 @@
+package llm
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HeuristicProvider implements Provider without any network access. It infers
+// a Conventional Commit header from file paths and the shape of the diff, so
+// it is deterministic and works offline.
+type HeuristicProvider struct{}
+
+// NewHeuristicProvider creates the offline heuristic provider.
+func NewHeuristicProvider() *HeuristicProvider {
+	return &HeuristicProvider{}
+}
+
+func (p *HeuristicProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk, 2)
+	if opts.Split {
+		ch <- StreamChunk{Content: heuristicSplit(diff)}
+	} else {
+		variants := heuristicMessages(diff)
+		ch <- StreamChunk{Content: variants[opts.Slot%len(variants)]}
+	}
+	ch <- StreamChunk{Done: true}
+	close(ch)
+	return ch, nil
+}
+
+// diffFile is the per-file summary of a unified diff used by the heuristics.
+type diffFile struct {
+	path    string
+	added   []string
+	removed []string
+	created bool
+	deleted bool
+}
+
+var (
+	identifierPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`),
+		regexp.MustCompile(`^\s*type\s+([A-Za-z_]\w*)\s`),
+		regexp.MustCompile(`^\s*(?:async\s+)?def\s+([A-Za-z_]\w*)`),
+		regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+([A-Za-z_$][\w$]*)`),
+		regexp.MustCompile(`^\s*(?:export\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)`),
+		regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?fn\s+([A-Za-z_]\w*)`),
+	}
+	goRequirePattern = regexp.MustCompile(`^\s*(?:require\s+)?([\w.\-]+(?:/[\w.\-~]+)+)\s+(v[\w.\-+]+)`)
+)
+
+func heuristicMessage(diff string) string {
+	return heuristicMessages(diff)[0]
+}
+
+// heuristicMessages returns distinct candidate headers, best first: the
+// inferred header, the same without its scope, and one with an alternative
+// type. Parallel slots take them in turn so they do not all repeat the same
+// suggestion.
+func heuristicMessages(diff string) []string {
+	files := parseDiffFiles(diff)
+	if len(files) == 0 {
+		return []string{"chore: update files"}
+	}
+
+	typ, desc := classifyFiles(files)
+	scope := commonScope(files)
+	if typ == "build" {
+		scope = "deps"
+	}
+	if desc == "" {
+		desc = describeChange(files, scope)
+	}
+
+	alt := "chore"
+	switch typ {
+	case "feat":
+		alt = "refactor"
+	case "refactor":
+		alt = "fix"
+	}
+
+	var headers []string
+	for _, h := range []string{
+		formatHeader(typ, scope, desc),
+		formatHeader(typ, "", desc),
+		formatHeader(alt, scope, desc),
+	} {
+		if !slices.Contains(headers, h) {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+// formatHeader builds a Conventional Commit header capped at 72 characters.
+func formatHeader(typ, scope, desc string) string {
+	header := typ
+	if scope != "" {
+		header += "(" + scope + ")"
+	}
+	header += ": " + desc
+	if runes := []rune(header); len(runes) > 72 {
+		header = strings.TrimSpace(string(runes[:72]))
+	}
+	return header
+}
+
+func parseDiffFiles(diff string) []diffFile {
+	var files []diffFile
+	var cur *diffFile
+	inHunk := false
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, diffFile{})
+			cur = &files[len(files)-1]
+			inHunk = false
+			if idx := strings.LastIndex(line, " b/"); idx >= 0 {
+				cur.path = line[idx+3:]
+			}
+		case cur == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+			switch {
+			case strings.HasPrefix(line, "new file mode"):
+				cur.created = true
+			case strings.HasPrefix(line, "deleted file mode"):
+				cur.deleted = true
+			case strings.HasPrefix(line, "+++ b/"):
+				cur.path = strings.TrimPrefix(line, "+++ b/")
+			}
+		case strings.HasPrefix(line, "+"):
+			cur.added = append(cur.added, line[1:])
+		case strings.HasPrefix(line, "-"):
+			cur.removed = append(cur.removed, line[1:])
+		}
+	}
+	return files
+}
+
+// classifyFiles picks the commit type from paths. A non-empty description is
+// returned when the path rule also determines it (dependency bumps).
+func classifyFiles(files []diffFile) (string, string) {
+	switch {
+	case allFiles(files, isTestFile):
+		return "test", ""
+	case allFiles(files, isDocFile):
+		return "docs", ""
+	case allFiles(files, isCIFile):
+		return "ci", ""
+	case allFiles(files, isDepFile):
+		return "build", describeDependencyBump(files)
+	}
+
+	// New declarations or files suggest a feature; anything else is treated
+	// as a restructuring since the heuristics cannot tell a bug fix apart.
+	added, removed, _ := identifierChanges(files)
+	if len(added) == 1 && len(removed) == 1 {
+		return "refactor", ""
+	}
+	if len(added) > 0 || allFiles(files, func(f diffFile) bool { return f.created }) {
+		return "feat", ""
+	}
+	return "refactor", ""
+}
+
+func allFiles(files []diffFile, pred func(diffFile) bool) bool {
+	for _, f := range files {
+		if !pred(f) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTestFile(f diffFile) bool {
+	base := path.Base(f.path)
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py")
+}
+
+func isDocFile(f diffFile) bool {
+	ext := strings.ToLower(path.Ext(f.path))
+	return ext == ".md" || ext == ".rst" || ext == ".adoc" || strings.HasPrefix(f.path, "docs/")
+}
+
+func isCIFile(f diffFile) bool {
+	return strings.HasPrefix(f.path, ".github/") || f.path == ".gitlab-ci.yml" || strings.HasPrefix(f.path, ".circleci/")
+}
+
+func isDepFile(f diffFile) bool {
+	switch path.Base(f.path) {
+	case "go.mod", "go.sum":
+		return true
+	}
+	return false
+}
+
+// describeDependencyBump summarizes go.mod require changes, e.g.
+// "bump openai-go to v3.21.0".
+func describeDependencyBump(files []diffFile) string {
+	for _, f := range files {
+		if path.Base(f.path) != "go.mod" {
+			continue
+		}
+		oldVersions := make(map[string]string)
+		for _, l := range f.removed {
+			if m := goRequirePattern.FindStringSubmatch(l); m != nil {
+				oldVersions[m[1]] = m[2]
+			}
+		}
+		var bumps []string
+		var newVersion string
+		for _, l := range f.added {
+			m := goRequirePattern.FindStringSubmatch(l)
+			if m == nil || strings.Contains(l, "// indirect") {
+				continue
+			}
+			if old, ok := oldVersions[m[1]]; ok && old != m[2] {
+				bumps = append(bumps, modulePathName(m[1]))
+				newVersion = m[2]
+			}
+		}
+		switch len(bumps) {
+		case 0:
+		case 1:
+			return "bump " + bumps[0] + " to " + newVersion
+		default:
+			return "bump " + joinNames(bumps)
+		}
+	}
+	return "update dependencies"
+}
+
+// modulePathName returns the last meaningful element of a module path,
+// skipping a trailing major version suffix like /v3.
+func modulePathName(mod string) string {
+	parts := strings.Split(mod, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	return name
+}
+
+// commonScope returns the last element of the deepest directory shared by all
+// files, skipping generic layout directories.
+func commonScope(files []diffFile) string {
+	var common []string
+	for i, f := range files {
+		dir := path.Dir(f.path)
+		if dir == "." {
+			return ""
+		}
+		parts := strings.Split(dir, "/")
+		if i == 0 {
+			common = parts
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	for i := len(common) - 1; i >= 0; i-- {
+		switch common[i] {
+		case "internal", "cmd", "pkg", "src", "lib", "app", ".github", "workflows", "docs", "test", "tests":
+			continue
+		}
+		return common[i]
+	}
+	return ""
+}
+
+// identifierChanges returns identifiers only declared in added lines, only in
+// removed lines, and in both.
+func identifierChanges(files []diffFile) (added, removed, changed []string) {
+	addSet := make(map[string]bool)
+	remSet := make(map[string]bool)
+	for _, f := range files {
+		for _, l := range f.added {
+			if id := declaredIdentifier(l); id != "" {
+				addSet[id] = true
+			}
+		}
+		for _, l := range f.removed {
+			if id := declaredIdentifier(l); id != "" {
+				remSet[id] = true
+			}
+		}
+	}
+	for id := range addSet {
+		if remSet[id] {
+			changed = append(changed, id)
+		} else {
+			added = append(added, id)
+		}
+	}
+	for id := range remSet {
+		if !addSet[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+func declaredIdentifier(line string) string {
+	for _, re := range identifierPatterns {
+		if m := re.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func describeChange(files []diffFile, scope string) string {
+	added, removed, changed := identifierChanges(files)
+	switch {
+	case len(added) == 1 && len(removed) == 1:
+		return "rename " + removed[0] + " to " + added[0]
+	case len(added) > 0:
+		return "add " + joinNames(added)
+	case len(removed) > 0:
+		return "remove " + joinNames(removed)
+	case len(changed) > 0:
+		return "update " + joinNames(changed)
+	}
+
+	if len(files) == 1 {
+		name := path.Base(files[0].path)
+		switch {
+		case files[0].created:
+			return "add " + name
+		case files[0].deleted:
+			return "remove " + name
+		default:
+			return "update " + name
+		}
+	}
+	if scope != "" {
+		return "update " + scope
+	}
+	return "update " + strconv.Itoa(len(files)) + " files"
+}
+
+// joinNames renders up to two names and a count for the rest.
+func joinNames(names []string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	default:
+		return names[0] + ", " + names[1] + " and " + strconv.Itoa(len(names)-2) + " more"
+	}
+}
@@ -0,0 +1,210 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHeuristicMessage(t *testing.T) {
+	cases := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "tests only",
+			diff: `diff --git a/internal/llm/prompt_test.go b/internal/llm/prompt_test.go
+--- a/internal/llm/prompt_test.go
++++ b/internal/llm/prompt_test.go
+@@ -1,3 +1,6 @@
++func TestParseEmpty(t *testing.T) {}
+`,
+			want: "test(llm): add TestParseEmpty",
+		},
+		{
+			name: "docs only",
+			diff: `diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1 +1 @@
+-old
++new
+`,
+			want: "docs: update README.md",
+		},
+		{
+			name: "ci only",
+			diff: `diff --git a/.github/workflows/dev.yml b/.github/workflows/dev.yml
+--- a/.github/workflows/dev.yml
++++ b/.github/workflows/dev.yml
+@@ -1 +1 @@
+-go-version: 1.24
++go-version: 1.25
+`,
+			want: "ci: update dev.yml",
+		},
+		{
+			name: "go.mod bump",
+			diff: `diff --git a/go.mod b/go.mod
+--- a/go.mod
++++ b/go.mod
+@@ -5,1 +5,1 @@
+-	github.com/openai/openai-go/v3 v3.20.0
++	github.com/openai/openai-go/v3 v3.21.0
+diff --git a/go.sum b/go.sum
+--- a/go.sum
++++ b/go.sum
+@@ -1 +1 @@
+-x
++y
+`,
+			want: "build(deps): bump openai-go to v3.21.0",
+		},
+		{
+			name: "new declarations",
+			diff: `diff --git a/internal/git/run.go b/internal/git/run.go
+new file mode 100644
+--- /dev/null
++++ b/internal/git/run.go
+@@ -0,0 +1,5 @@
++func run(args ...string) error {
++func output(args ...string) ([]byte, error) {
+`,
+			want: "feat(git): add output and run",
+		},
+		{
+			name: "rename",
+			diff: `diff --git a/internal/tui/app.go b/internal/tui/app.go
+--- a/internal/tui/app.go
++++ b/internal/tui/app.go
+@@ -1,1 +1,1 @@
+-func startGen() tea.Cmd {
++func startGeneration() tea.Cmd {
+`,
+			want: "refactor(tui): rename startGen to startGeneration",
+		},
+		{
+			name: "removed line starting with dashes stays content",
+			diff: `diff --git a/schema.sql b/schema.sql
+--- a/schema.sql
++++ b/schema.sql
+@@ -1,1 +0,0 @@
+--- legacy table
+`,
+			want: "refactor: update schema.sql",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := heuristicMessage(tc.diff); got != tc.want {
+				t.Fatalf("heuristicMessage() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+type failingProvider struct{ err error }
+
+func (p failingProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk, 1)
+	ch <- StreamChunk{Err: p.err}
+	close(ch)
+	return ch, nil
+}
+
+func TestFallbackProviderUsesHeuristicWhenUnreachable(t *testing.T) {
+	unreachable := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	p := NewFallbackProvider(failingProvider{err: unreachable}, NewHeuristicProvider())
+
+	diff := "diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-a\n+b\n"
+	events := GenerateMultiple(context.Background(), p, diff, GenerateOptions{}, 1)
+	var got IndexedMessageEvent
+	for ev := range events {
+		if ev.Done || ev.Err != nil {
+			got = ev
+		}
+	}
+	if got.Err != nil || got.Suggestion.Header() != "docs: update README.md" {
+		t.Fatalf("got %+v, want heuristic docs message", got)
+	}
+	if !got.Suggestion.Offline {
+		t.Fatalf("fallback suggestion not marked offline")
+	}
+}
+
+type refusingProvider struct{ err error }
+
+func (p refusingProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
+	return nil, p.err
+}
+
+func TestFallbackProviderMarksOfflineOnStartError(t *testing.T) {
+	unreachable := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	p := NewFallbackProvider(refusingProvider{err: unreachable}, NewHeuristicProvider())
+
+	ch, err := p.GenerateCommitMessages(context.Background(), "diff --git a/a.go b/a.go\n", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for chunk := range ch {
+		if !chunk.Offline {
+			t.Fatalf("chunk %+v not marked offline", chunk)
+		}
+	}
+}
+
+func TestFallbackProviderKeepsAPIErrors(t *testing.T) {
+	apiErr := errors.New("401 unauthorized")
+	p := NewFallbackProvider(failingProvider{err: apiErr}, NewHeuristicProvider())
+
+	ch, err := p.GenerateCommitMessages(context.Background(), "", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := <-ch
+	if !errors.Is(chunk.Err, apiErr) {
+		t.Fatalf("got %+v, want original API error", chunk)
+	}
+}
+
+func TestHeuristicProviderVariesSlots(t *testing.T) {
+	diff := `diff --git a/internal/git/run.go b/internal/git/run.go
+new file mode 100644
+--- /dev/null
++++ b/internal/git/run.go
+@@ -0,0 +1,1 @@
++func run(args ...string) error {
+`
+	want := []string{"feat(git): add run", "feat: add run", "refactor(git): add run"}
+	seen := make(map[string]bool)
+	for ev := range GenerateMultiple(context.Background(), NewHeuristicProvider(), diff, GenerateOptions{}, 3) {
+		if ev.Err != nil {
+			t.Fatalf("slot %d: %v", ev.Index, ev.Err)
+		}
+		if ev.Done {
+			if got := ev.Suggestion.Header(); got != want[ev.Index] {
+				t.Errorf("slot %d = %q, want %q", ev.Index, got, want[ev.Index])
+			}
+			seen[ev.Suggestion.Header()] = true
+		}
+	}
+	if len(seen) != 3 {
+		t.Fatalf("got %d distinct suggestions, want 3", len(seen))
+	}
+}
+
+func TestFormatHeaderTruncatesOnRuneBoundary(t *testing.T) {
+	desc := "update " + strings.Repeat("配置", 40) + ".go"
+	got := formatHeader("chore", "", desc)
+	if !utf8.ValidString(got) {
+		t.Fatalf("formatHeader() split a character: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n != 72 {
+		t.Fatalf("formatHeader() has %d characters, want 72", n)
+	}
+}
@@ -0,0 +1,305 @@
+package llm
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LanguageAuto asks for the language to be detected from recent commit
+// subjects (see ResolveLanguage).
+const LanguageAuto = "auto"
+
+// Language describes a commit message language.
+type Language struct {
+	// Tag is the BCP-47 tag stored in the config.
+	Tag string
+	// Name is the English name used in prompts.
+	Name string
+	// Native is the name shown in pickers.
+	Native string
+}
+
+var languages = []Language{
+	{"en", "English", "English"},
+	{"zh", "Simplified Chinese", "简体中文"},
+	{"zh-Hant", "Traditional Chinese", "繁體中文"},
+	{"ja", "Japanese", "日本語"},
+	{"ko", "Korean", "한국어"},
+	{"es", "Spanish", "Español"},
+	{"fr", "French", "Français"},
+	{"de", "German", "Deutsch"},
+	{"it", "Italian", "Italiano"},
+	{"pt", "Portuguese", "Português"},
+	{"pt-BR", "Brazilian Portuguese", "Português (Brasil)"},
+	{"nl", "Dutch", "Nederlands"},
+	{"pl", "Polish", "Polski"},
+	{"tr", "Turkish", "Türkçe"},
+	{"ru", "Russian", "Русский"},
+	{"uk", "Ukrainian", "Українська"},
+	{"cs", "Czech", "Čeština"},
+	{"sv", "Swedish", "Svenska"},
+	{"da", "Danish", "Dansk"},
+	{"nb", "Norwegian Bokmål", "Norsk bokmål"},
+	{"fi", "Finnish", "Suomi"},
+	{"el", "Greek", "Ελληνικά"},
+	{"hu", "Hungarian", "Magyar"},
+	{"ro", "Romanian", "Română"},
+	{"vi", "Vietnamese", "Tiếng Việt"},
+	{"id", "Indonesian", "Bahasa Indonesia"},
+	{"ms", "Malay", "Bahasa Melayu"},
+	{"th", "Thai", "ไทย"},
+	{"hi", "Hindi", "हिन्दी"},
+	{"bn", "Bengali", "বাংলা"},
+	{"ar", "Arabic", "العربية"},
+	{"he", "Hebrew", "עברית"},
+	{"fa", "Persian", "فارسی"},
+}
+
+// languageAliases maps alternative tags onto entries in languages.
+var languageAliases = map[string]string{
+	"zh-Hans": "zh",
+	"zh-CN":   "zh",
+	"zh-SG":   "zh",
+	"zh-TW":   "zh-Hant",
+	"zh-HK":   "zh-Hant",
+	"zh-MO":   "zh-Hant",
+	"no":      "nb",
+	"iw":      "he",
+}
+
+var bcp47Pattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Languages returns the languages with a known display name.
+func Languages() []Language {
+	return append([]Language(nil), languages...)
+}
+
+// LanguageName returns the name used in prompts for a BCP-47 tag. Tags
+// without a table entry fall back to their base language, or are passed to
+// the model as-is when well-formed; anything else means English.
+func LanguageName(tag string) string {
+	tag = canonicalTag(tag)
+	if tag == "" {
+		return "English"
+	}
+	if l, ok := lookupLanguage(tag); ok {
+		return l.Name
+	}
+	if base, _, ok := strings.Cut(tag, "-"); ok {
+		if l, ok := lookupLanguage(base); ok {
+			return l.Name + " (" + tag + ")"
+		}
+	}
+	if bcp47Pattern.MatchString(tag) {
+		return "the language with BCP-47 tag " + tag
+	}
+	return "English"
+}
+
+func lookupLanguage(tag string) (Language, bool) {
+	if alias, ok := languageAliases[tag]; ok {
+		tag = alias
+	}
+	for _, l := range languages {
+		if l.Tag == tag {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+// canonicalTag normalizes case and separators: "pt_br" -> "pt-BR",
+// "zh-hant" -> "zh-Hant".
+func canonicalTag(tag string) string {
+	parts := strings.FieldsFunc(strings.TrimSpace(tag), func(r rune) bool { return r == '-' || r == '_' })
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 4:
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p)
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// ResolveLanguage returns lang unless it is LanguageAuto, in which case the
+// dominant language of subjects is detected, falling back to English.
+func ResolveLanguage(lang string, subjects []string) string {
+	if !strings.EqualFold(strings.TrimSpace(lang), LanguageAuto) {
+		return lang
+	}
+	if detected := DetectLanguage(subjects); detected != "" {
+		return detected
+	}
+	return "en"
+}
+
+// DetectLanguage returns the tag of the language used by most commit
+// subjects, or "" when there is no clear majority. It looks at scripts and
+// common words only and never uses the network.
+func DetectLanguage(subjects []string) string {
+	votes := make(map[string]int)
+	total := 0
+	for _, s := range subjects {
+		if lang := detectSubjectLanguage(ParseHeader(s).Description); lang != "" {
+			votes[lang]++
+			total++
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+
+	tags := make([]string, 0, len(votes))
+	for tag := range votes {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if votes[tags[i]] != votes[tags[j]] {
+			return votes[tags[i]] > votes[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	if best := tags[0]; votes[best]*2 > total {
+		return best
+	}
+	return ""
+}
+
+// Characters that only occur in one of the Chinese scripts, used to tell
+// Simplified from Traditional Chinese.
+const (
+	simplifiedOnly  = "们这个为与发现后时会来说对过还应点开关于体门问题复删"
+	traditionalOnly = "們這個為與發現後時會來說對過還應點開關於體門問題復刪"
+)
+
+// detectSubjectLanguage classifies one subject by script, then by common
+// words and letters for Latin-script text.
+func detectSubjectLanguage(s string) string {
+	var han, kana, hangul, cyrillic, arabic, hebrew, thai, devanagari, bengali, greek, latin int
+	var simplified, traditional int
+	var ukrainian, persian bool
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+			if strings.ContainsRune(simplifiedOnly, r) {
+				simplified++
+			} else if strings.ContainsRune(traditionalOnly, r) {
+				traditional++
+			}
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			ukrainian = ukrainian || strings.ContainsRune("іїєґІЇЄҐ", r)
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+			persian = persian || strings.ContainsRune("پچژگ", r)
+		case unicode.Is(unicode.Hebrew, r):
+			hebrew++
+		case unicode.Is(unicode.Thai, r):
+			thai++
+		case unicode.Is(unicode.Devanagari, r):
+			devanagari++
+		case unicode.Is(unicode.Bengali, r):
+			bengali++
+		case unicode.Is(unicode.Greek, r):
+			greek++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	switch {
+	case kana > 0:
+		return "ja"
+	case hangul > 0:
+		return "ko"
+	case han > 0:
+		if traditional > simplified {
+			return "zh-Hant"
+		}
+		return "zh"
+	case cyrillic > latin:
+		if ukrainian {
+			return "uk"
+		}
+		return "ru"
+	case arabic > latin:
+		if persian {
+			return "fa"
+		}
+		return "ar"
+	case hebrew > latin:
+		return "he"
+	case thai > latin:
+		return "th"
+	case devanagari > latin:
+		return "hi"
+	case bengali > latin:
+		return "bn"
+	case greek > latin:
+		return "el"
+	case latin > 0:
+		return detectLatinLanguage(s)
+	}
+	return ""
+}
+
+// latinHints lists common commit words and distinctive letters per language.
+var latinHints = []struct {
+	tag     string
+	words   []string
+	letters string
+}{
+	{"en", []string{"the", "and", "for", "with", "to", "of", "in", "add", "fix", "update", "remove", "use", "support", "when", "from", "allow", "handle", "make", "bump", "improve"}, ""},
+	{"es", []string{"el", "la", "los", "las", "de", "del", "para", "con", "agregar", "añadir", "corregir", "actualizar", "eliminar", "soporte", "por"}, "ñ¿¡"},
+	{"fr", []string{"le", "la", "les", "des", "du", "pour", "avec", "ajouter", "ajout", "corriger", "correction", "mise", "jour", "supprimer", "une", "un"}, "èêëàâîïûùœ"},
+	{"de", []string{"der", "die", "das", "und", "für", "mit", "hinzufügen", "behebe", "beheben", "aktualisiere", "aktualisieren", "entferne", "entfernen", "nicht", "ein", "eine"}, "ßäöü"},
+	{"it", []string{"il", "lo", "gli", "della", "per", "con", "aggiungi", "aggiunto", "correggi", "aggiorna", "rimuovi", "supporto", "non"}, "ìò"},
+	{"pt", []string{"o", "os", "as", "do", "da", "dos", "das", "para", "com", "adiciona", "adicionar", "corrige", "corrigir", "atualiza", "atualizar", "remove", "suporte", "não"}, "ãõ"},
+	{"nl", []string{"de", "het", "een", "en", "voor", "met", "toevoegen", "voeg", "repareer", "bijwerken", "verwijder", "niet", "van"}, "ĳ"},
+	{"pl", []string{"dodaj", "popraw", "usuń", "aktualizuj", "dla", "oraz", "nie", "się", "w", "z"}, "ąęłśźżńć"},
+	{"tr", []string{"ve", "için", "ile", "ekle", "düzelt", "güncelle", "kaldır", "bir"}, "ğşı"},
+	{"vi", []string{"thêm", "sửa", "cập", "nhật", "xóa", "và", "cho", "của", "lỗi", "không"}, "ơưđạảấầẩẫậắằẳẵặẹẻẽếềểễệỉịọỏốồổỗộớờởỡợụủứừửữựỳỵỷỹ"},
+	{"id", []string{"dan", "untuk", "dengan", "tambah", "tambahkan", "perbaiki", "perbarui", "hapus", "yang", "tidak"}, ""},
+}
+
+var wordPattern = regexp.MustCompile(`[\p{L}']+`)
+
+func detectLatinLanguage(s string) string {
+	lower := strings.ToLower(s)
+	words := wordPattern.FindAllString(lower, -1)
+
+	best, bestScore := "", 0
+	for _, h := range latinHints {
+		score := 0
+		for _, w := range words {
+			for _, hw := range h.words {
+				if w == hw {
+					score += 2
+					break
+				}
+			}
+		}
+		for _, r := range h.letters {
+			if strings.ContainsRune(lower, r) {
+				score += 3
+			}
+		}
+		if score > bestScore {
+			best, bestScore = h.tag, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,64 @@
+package llm
+
+import "testing"
+
+func TestLanguageName(t *testing.T) {
+	tests := map[string]string{
+		"":        "English",
+		"en":      "English",
+		"zh":      "Simplified Chinese",
+		"zh-CN":   "Simplified Chinese",
+		"zh_tw":   "Traditional Chinese",
+		"zh-hant": "Traditional Chinese",
+		"pt-BR":   "Brazilian Portuguese",
+		"pt":      "Portuguese",
+		"vi":      "Vietnamese",
+		"es-MX":   "Spanish (es-MX)",
+		"sw":      "the language with BCP-47 tag sw",
+		"???":     "English",
+	}
+	for tag, want := range tests {
+		if got := LanguageName(tag); got != want {
+			t.Errorf("LanguageName(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		subjects []string
+		want     string
+	}{
+		{"english", []string{"feat(api): add retry support", "fix: handle empty config", "docs: update the README"}, "en"},
+		{"simplified chinese", []string{"feat: 添加重试支持", "fix(config): 修复空配置问题", "docs: 更新文档"}, "zh"},
+		{"traditional chinese", []string{"feat: 新增這個選項", "fix: 修正發佈後的問題"}, "zh-Hant"},
+		{"japanese", []string{"feat: リトライを追加", "fix: 設定の読み込みを修正する"}, "ja"},
+		{"korean", []string{"feat: 재시도 지원 추가", "fix: 빈 설정 처리"}, "ko"},
+		{"portuguese", []string{"feat: adiciona suporte a novas opções", "fix: corrige a validação do formulário", "docs: atualiza o guia"}, "pt"},
+		{"vietnamese", []string{"feat: thêm hỗ trợ thử lại", "fix: sửa lỗi cấu hình rỗng"}, "vi"},
+		{"german", []string{"feat: füge Unterstützung für Proxys hinzu", "fix: behebe Absturz beim Start"}, "de"},
+		{"russian", []string{"feat: добавить поддержку прокси", "fix: исправить ошибку запуска"}, "ru"},
+		{"no signal", []string{"v1.2.3", "1234"}, ""},
+		{"no majority", []string{"fix: handle empty config", "fix: 修复空配置问题"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.subjects); got != tt.want {
+				t.Fatalf("DetectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveLanguage(t *testing.T) {
+	if got := ResolveLanguage("de", []string{"fix: 修复问题"}); got != "de" {
+		t.Fatalf("explicit language overridden: %q", got)
+	}
+	if got := ResolveLanguage("auto", []string{"fix: 修复问题", "feat: 添加功能"}); got != "zh" {
+		t.Fatalf("ResolveLanguage(auto) = %q, want zh", got)
+	}
+	if got := ResolveLanguage("auto", nil); got != "en" {
+		t.Fatalf("ResolveLanguage(auto) without history = %q, want en", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared"
 )
 
 // OpenAIProvider implements Provider using the official OpenAI SDK.
@@ -14,21 +15,32 @@ type OpenAIProvider struct {
 }
 
 // NewOpenAIProvider creates a provider for the official OpenAI API.
-func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+// Extra options (HTTP client, headers) are applied after the API key.
+func NewOpenAIProvider(apiKey, model string, opts ...option.RequestOption) *OpenAIProvider {
+	client := openai.NewClient(append([]option.RequestOption{option.WithAPIKey(apiKey)}, opts...)...)
 	return &OpenAIProvider{client: &client, model: model}
 }
 
 func (p *OpenAIProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
 	ch := make(chan StreamChunk, 64)
 
-	stream := p.client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
-		Model: p.model,
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(buildSystemPrompt(opts.Language)),
-			openai.UserMessage(buildUserPrompt(diff)),
-		},
-	})
+	params := openai.ChatCompletionNewParams{
+		Model:    p.model,
+		Messages: openAIMessages(diff, opts),
+	}
+	if opts.Structured {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   suggestionToolName,
+					Strict: openai.Bool(true),
+					Schema: suggestionJSONSchema(),
+				},
+			},
+		}
+	}
+
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
 
 	go func() {
 		defer close(ch)
@@ -50,3 +62,37 @@ func (p *OpenAIProvider) GenerateCommitMessages(ctx context.Context, diff string
 
 	return ch, nil
 }
+
+// openAIMessages builds the system prompt, the diff prompt and any
+// follow-up turns for the chat completions API.
+func openAIMessages(diff string, opts GenerateOptions) []openai.ChatCompletionMessageParamUnion {
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(systemPromptFor(opts)),
+		openai.UserMessage(buildUserPromptFor(diff, opts)),
+	}
+	for _, m := range opts.Messages {
+		if m.Role == RoleAssistant {
+			messages = append(messages, openai.AssistantMessage(m.Content))
+		} else {
+			messages = append(messages, openai.UserMessage(m.Content))
+		}
+	}
+	return messages
+}
+
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]string, error) {
+	return listOpenAIModels(ctx, p.client)
+}
+
+// listOpenAIModels pages through the /models endpoint of an OpenAI-compatible API.
+func listOpenAIModels(ctx context.Context, client *openai.Client) ([]string, error) {
+	var ids []string
+	iter := client.Models.ListAutoPaging(ctx)
+	for iter.Next() {
+		ids = append(ids, iter.Current().ID)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -15,23 +15,23 @@ type OpenAICompatProvider struct {
 }
 
 // NewOpenAICompatProvider creates a provider using an OpenAI-compatible API endpoint.
-func NewOpenAICompatProvider(apiKey, model, baseURL string) *OpenAICompatProvider {
-	client := openai.NewClient(
+// Extra options (HTTP client, headers) are applied after the API key and base URL.
+func NewOpenAICompatProvider(apiKey, model, baseURL string, opts ...option.RequestOption) *OpenAICompatProvider {
+	client := openai.NewClient(append([]option.RequestOption{
 		option.WithAPIKey(apiKey),
 		option.WithBaseURL(baseURL),
-	)
+	}, opts...)...)
 	return &OpenAICompatProvider{client: &client, model: model}
 }
 
 func (p *OpenAICompatProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
 	ch := make(chan StreamChunk, 64)
 
+	// Compatible endpoints vary in response_format support, so structured
+	// mode relies on the strict JSON prompt alone.
 	stream := p.client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
-		Model: p.model,
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(buildSystemPrompt(opts.Language)),
-			openai.UserMessage(buildUserPrompt(diff)),
-		},
+		Model:    p.model,
+		Messages: openAIMessages(diff, opts),
 	})
 
 	go func() {
@@ -54,3 +54,7 @@ func (p *OpenAICompatProvider) GenerateCommitMessages(ctx context.Context, diff
 
 	return ch, nil
 }
+
+func (p *OpenAICompatProvider) ListModels(ctx context.Context) ([]string, error) {
+	return listOpenAIModels(ctx, p.client)
+}
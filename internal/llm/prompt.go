@@ -5,29 +5,21 @@ import (
 	"strings"
 )
 
-func buildSystemPrompt(lang string) string {
-	langInstruction := "English"
-	switch lang {
-	case "zh", "zh-CN", "zh-Hans":
-		langInstruction = "Simplified Chinese"
-	case "zh-TW", "zh-Hant":
-		langInstruction = "Traditional Chinese"
-	case "ja":
-		langInstruction = "Japanese"
-	case "ko":
-		langInstruction = "Korean"
-	case "es":
-		langInstruction = "Spanish"
-	case "fr":
-		langInstruction = "French"
-	case "de":
-		langInstruction = "German"
-	case "ru":
-		langInstruction = "Russian"
-	default:
-		langInstruction = "English"
+// Commit message conventions accepted in GenerateOptions.Convention.
+const (
+	ConventionConventional = "conventional"
+	ConventionPlain        = "plain"
+)
+
+// systemPromptFor returns the system prompt for the requested convention.
+func systemPromptFor(opts GenerateOptions) string {
+	if opts.Convention == ConventionPlain {
+		return buildPlainSystemPrompt(opts.Language)
 	}
+	return buildSystemPrompt(opts.Language)
+}
 
+func buildSystemPrompt(lang string) string {
 	return fmt.Sprintf(`You write Git commit messages following Conventional Commits 1.0.0.
 
 Task:
@@ -85,17 +77,112 @@ Bad examples:
 - update files
 - fix: fix bug
 - chore: add new public endpoint
-- feat: update README only`, langInstruction)
+- feat: update README only`, LanguageName(lang))
 }
 
-func buildUserPrompt(diff string) string {
-	return fmt.Sprintf(`Analyze this git diff and write one Conventional Commit header.
+// buildPlainSystemPrompt is used for repositories that do not follow
+// Conventional Commits and expect a plain imperative subject line.
+func buildPlainSystemPrompt(lang string) string {
+	return fmt.Sprintf(`You write Git commit subject lines in plain imperative style.
+
+Task:
+1) Infer the primary intent of the diff.
+2) Output one commit subject line only.
+
+Writing rules:
+- Start with a capitalized imperative verb (Add, Fix, Remove, Update...)
+- Do not use a type prefix such as "feat:" or "fix(scope):"
+- Target <= 50 characters; hard limit <= 72 characters
+- No trailing period
+- Focus on intent/outcome, not file-by-file listing
+- Output raw message only: no quotes, no markdown, no extra lines
+- Write in %s
+
+Good examples:
+- Add OAuth2 login flow
+- Handle empty env var fallback in config loader
+- Reduce allocations in cache key lookup
+
+Bad examples:
+- update files
+- feat: add login
+- Fixed bug.`, LanguageName(lang))
+}
+
+const headerInstructions = `Analyze this git diff and write one Conventional Commit header.
 
 First, silently determine the primary change type using the system rubric.
-Then output only one raw commit message line (no quotes, no markdown, no explanation).
+Then output only one raw commit message line (no quotes, no markdown, no explanation).`
+
+const plainInstructions = `Analyze this git diff and write one commit subject line.
+
+Output only the raw subject line (no quotes, no markdown, no explanation).`
+
+// structuredInstructions ask for the suggestion as a JSON object instead of
+// a raw header line. The system prompt rules still apply to each field.
+const structuredInstructions = `Analyze this git diff and propose one Conventional Commit.
+
+Apply the system rubric and writing rules to choose the type, scope and description,
+but instead of a raw header line respond with a single JSON object and nothing else:
+{"type": "<type>", "scope": "<scope or empty>", "breaking": <true|false>,
+ "description": "<imperative summary without the type/scope prefix>",
+ "body": "<optional wrapped explanation of what and why, or empty>",
+ "footers": ["<optional footer such as BREAKING CHANGE: ...>"],
+ "rationale": "<one sentence explaining the chosen type and scope>"}`
+
+// buildUserPromptPrefix returns the stable part of the user prompt: task
+// instructions and repository history examples. It is identical across the
+// parallel requests of a run, so it comes before the volatile diff to keep
+// the cacheable prompt prefix as long as possible.
+func buildUserPromptPrefix(opts GenerateOptions) string {
+	var b strings.Builder
+	switch {
+	case opts.Split:
+		b.WriteString(splitInstructions)
+	case opts.Convention == ConventionPlain:
+		b.WriteString(plainInstructions)
+	case opts.Structured:
+		b.WriteString(structuredInstructions)
+	default:
+		b.WriteString(headerInstructions)
+	}
+
+	if len(opts.History) > 0 {
+		b.WriteString("\n\nRecent commit subjects in this repository (match their scope names and conventions, not their content):")
+		for _, h := range opts.History {
+			b.WriteString("\n- ")
+			b.WriteString(h)
+		}
+	}
+
+	if prev := strings.TrimSpace(opts.PreviousMessage); prev != "" {
+		b.WriteString("\n\nThe diff amends an existing commit whose message is below. Keep what still applies and describe the combined change:\n")
+		b.WriteString(prev)
+	}
 
-Git diff:
-%s`, diff)
+	b.WriteString("\n\nGit diff:\n")
+	return b.String()
+}
+
+// RefinementMessages returns the turns that ask the model to revise
+// candidate according to the user's feedback.
+func RefinementMessages(candidate, feedback string) []Message {
+	return []Message{
+		{Role: RoleAssistant, Content: candidate},
+		{Role: RoleUser, Content: fmt.Sprintf(`Revise the commit message above based on this feedback:
+%s
+
+Keep following the same rules and output format.`, strings.TrimSpace(feedback))},
+	}
+}
+
+// buildUserPromptFor joins the stable prefix and the diff.
+func buildUserPromptFor(diff string, opts GenerateOptions) string {
+	return buildUserPromptPrefix(opts) + diff
+}
+
+func buildUserPrompt(diff string) string {
+	return buildUserPromptFor(diff, GenerateOptions{})
 }
 
 // parseMessage extracts a single commit message from the LLM response.
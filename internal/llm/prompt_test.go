@@ -67,3 +67,91 @@ func TestParseMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSuggestionStructured(t *testing.T) {
+	raw := "```json\n{\"type\":\"feat\",\"scope\":\"api\",\"breaking\":true,\"description\":\"feat(api): drop v1 endpoints\",\"body\":\"Clients must use v2.\",\"footers\":[\"BREAKING CHANGE: v1 removed\"],\"rationale\":\"removes public API\"}\n```"
+
+	got, ok := parseSuggestion(raw, true)
+	if !ok {
+		t.Fatalf("parseSuggestion() failed")
+	}
+	if got.Header() != "feat(api)!: drop v1 endpoints" {
+		t.Fatalf("Header() = %q", got.Header())
+	}
+	want := "feat(api)!: drop v1 endpoints\n\nClients must use v2.\n\nBREAKING CHANGE: v1 removed"
+	if got.Message() != want {
+		t.Fatalf("Message() = %q, want %q", got.Message(), want)
+	}
+	if got.Rationale != "removes public API" {
+		t.Fatalf("Rationale = %q", got.Rationale)
+	}
+}
+
+func TestParseSuggestionFallsBackOnMalformedJSON(t *testing.T) {
+	got, ok := parseSuggestion("1. fix(config): handle empty env var", true)
+	if !ok {
+		t.Fatalf("parseSuggestion() failed")
+	}
+	if got.Type != "fix" || got.Scope != "config" || got.Description != "handle empty env var" {
+		t.Fatalf("parseSuggestion() = %+v", got)
+	}
+}
+
+func TestStreamPreviewStructured(t *testing.T) {
+	cases := []struct {
+		raw        string
+		structured bool
+		want       string
+	}{
+		{`{"type":"feat","sco`, true, ""},
+		{`{"type":"feat","scope":"api","breaking":false,"description":"add pag`, true, "feat(api): add pag"},
+		{`{"type":"fix","scope":"","breaking":false,"description":"quote \"paths\`, true, `fix: quote "paths`},
+		{"fix(git): quote paths", true, "fix(git): quote paths"},
+		{`{"type":"feat"`, false, `{"type":"feat"`},
+	}
+	for _, tc := range cases {
+		if got := StreamPreview(tc.raw, tc.structured); got != tc.want {
+			t.Errorf("StreamPreview(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestParseHeaderFreeForm(t *testing.T) {
+	got := ParseHeader("Merge branch 'main'")
+	if got.Type != "" || got.Header() != "Merge branch 'main'" {
+		t.Fatalf("ParseHeader() = %+v", got)
+	}
+}
+
+func TestUserPromptIncludesPreviousMessage(t *testing.T) {
+	opts := GenerateOptions{PreviousMessage: "fix(git): quote paths\n\nHandles spaces.\n"}
+	prefix := buildUserPromptPrefix(opts)
+	if !strings.Contains(prefix, "fix(git): quote paths\n\nHandles spaces.\n\nGit diff:\n") {
+		t.Fatalf("prefix should end with the previous message before the diff, got %q", prefix)
+	}
+	if strings.Contains(buildUserPromptPrefix(GenerateOptions{}), "amends") {
+		t.Fatalf("prefix without a previous message must not mention amending")
+	}
+}
+
+func TestUserPromptStablePrefixPrecedesDiff(t *testing.T) {
+	opts := GenerateOptions{History: []string{"feat(tui): add diff pane", "fix(git): quote paths"}}
+	diff := "diff --git a/x.go b/x.go\n+x"
+
+	prefix := buildUserPromptPrefix(opts)
+	got := buildUserPromptFor(diff, opts)
+	if got != prefix+diff {
+		t.Fatalf("user prompt must be the stable prefix followed by the diff")
+	}
+	if strings.Contains(prefix, "diff --git") {
+		t.Fatalf("prefix must not contain the diff")
+	}
+	for _, h := range opts.History {
+		if !strings.Contains(prefix, "- "+h) {
+			t.Fatalf("prefix missing history example %q", h)
+		}
+	}
+	if !strings.HasSuffix(prefix, "Git diff:\n") {
+		t.Fatalf("prefix should end right before the diff, got %q", prefix)
+	}
+}
@@ -2,29 +2,143 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/lieyanc/fire-commit/internal/debuglog"
 )
 
 // StreamChunk represents a piece of streamed text from the LLM.
+// The Done chunk may carry token Usage.
 type StreamChunk struct {
 	Content string
 	Done    bool
+	Usage   *Usage
 	Err     error
+	// Offline marks chunks from the offline heuristic standing in for an
+	// unreachable provider (see FallbackProvider).
+	Offline bool
 }
 
 // GenerateOptions holds options for commit message generation.
 type GenerateOptions struct {
 	Language string
+	// Structured asks the provider for a JSON suggestion (schema, tool use or
+	// a strict JSON prompt) instead of a raw header line.
+	Structured bool
+	// Convention selects the message style: ConventionConventional (the
+	// default when empty) or ConventionPlain.
+	Convention string
+	// Messages continue the conversation after the initial diff prompt, e.g.
+	// a previous candidate and the user's feedback on it (see
+	// RefinementMessages). Empty for a fresh generation.
+	Messages []Message
+	// History holds recent commit subjects shown to the model as examples of
+	// the repository's conventions.
+	History []string
+	// Split asks for a JSON plan that groups the numbered hunks of the diff
+	// into commits instead of a single message (see GenerateSplit).
+	Split bool
+	// PreviousMessage is the message of a commit being amended, shown to the
+	// model as context for the combined diff. Empty for a new commit.
+	PreviousMessage string
+	// Slot is the index of the request within GenerateMultiple. Providers
+	// that cannot sample, such as the heuristic one, use it to vary their
+	// answers across slots.
+	Slot int
+
+	// MaxConcurrency limits how many requests GenerateMultiple runs at once
+	// (0 = all at once).
+	MaxConcurrency int
+	// RequestTimeout bounds each request from start to final chunk (0 = none).
+	RequestTimeout time.Duration
+	// FirstTokenTimeout bounds the wait for the first streamed text (0 = none).
+	FirstTokenTimeout time.Duration
+}
+
+// Message roles used in GenerateOptions.Messages.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one conversation turn sent after the initial prompt.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Usage reports token accounting for one request when the provider exposes it.
+type Usage struct {
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+}
+
+// Add accumulates u into the receiver.
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheCreationTokens += other.CacheCreationTokens
+	u.CacheReadTokens += other.CacheReadTokens
 }
 
 // Provider is the interface that all LLM providers must implement.
+//
+// A request is a conversation: the system prompt, the user prompt with the
+// diff, then opts.Messages in order as further user and assistant turns
+// (e.g. a refinement, see RefinementMessages). The OpenAI, OpenAI-compatible,
+// Ollama and Anthropic providers send them as chat messages, the exec
+// provider passes them in ExecRequest.Messages, and FallbackProvider forwards
+// them unchanged. The heuristic provider cannot converse; it ignores them and
+// answers as for a fresh generation.
 type Provider interface {
 	// GenerateCommitMessages generates a single commit message suggestion.
 	// It returns a channel of StreamChunk for streaming the response.
 	GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error)
 }
 
+// ModelLister is an optional capability for providers that can enumerate the
+// models available to the configured credentials.
+type ModelLister interface {
+	// ListModels returns the model IDs served by the provider.
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// ErrListModelsUnsupported is returned by ListModels when the provider does
+// not implement ModelLister.
+var ErrListModelsUnsupported = errors.New("provider does not support listing models")
+
+// ListModels returns the sorted, de-duplicated model IDs offered by provider.
+func ListModels(ctx context.Context, provider Provider) ([]string, error) {
+	lister, ok := provider.(ModelLister)
+	if !ok {
+		return nil, ErrListModelsUnsupported
+	}
+	ids, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(ids))
+	models := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		models = append(models, id)
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
 // IndexedMessage holds the result of a single parallel LLM request.
 type IndexedMessage struct {
 	Index   int
@@ -33,17 +147,28 @@ type IndexedMessage struct {
 }
 
 // IndexedMessageEvent is a streamed event from one parallel LLM request.
-// Delta carries incremental text chunks. A terminal event has Done=true or Err.
+// Delta carries incremental text chunks. A terminal event has Done=true with
+// the parsed Suggestion, or Err.
 type IndexedMessageEvent struct {
-	Index   int
-	Delta   string
-	Content string
-	Done    bool
-	Err     error
+	Index      int
+	Delta      string
+	Suggestion Suggestion
+	Usage      *Usage
+	Done       bool
+	Err        error
 }
 
-// GenerateMultiple launches n independent requests in parallel and streams
-// chunk-level events for each request.
+// Errors reported for generation slots that did not produce a suggestion.
+var (
+	ErrRequestTimeout    = errors.New("request timed out")
+	ErrFirstTokenTimeout = errors.New("no response before first-token timeout")
+	ErrEmptyResponse     = errors.New("model returned an empty response")
+)
+
+// GenerateMultiple launches n independent requests and streams chunk-level
+// events for each request. At most opts.MaxConcurrency requests run at once;
+// the rest wait for a free slot. Each request is bounded by opts.RequestTimeout and
+// opts.FirstTokenTimeout.
 func GenerateMultiple(ctx context.Context, provider Provider, diff string, opts GenerateOptions, n int) <-chan IndexedMessageEvent {
 	buffer := n * 16
 	if buffer < 64 {
@@ -52,58 +177,43 @@ func GenerateMultiple(ctx context.Context, provider Provider, diff string, opts
 	ch := make(chan IndexedMessageEvent, buffer)
 	var wg sync.WaitGroup
 
+	// Structured suggestions are built around Conventional Commit fields.
+	if opts.Convention == ConventionPlain {
+		opts.Structured = false
+	}
+
+	if debuglog.Enabled() {
+		debuglog.Log("llm.generate", map[string]any{
+			"provider":        fmt.Sprintf("%T", provider),
+			"n":               n,
+			"language":        opts.Language,
+			"structured":      opts.Structured,
+			"diff_bytes":      len(diff),
+			"max_concurrency": opts.MaxConcurrency,
+		})
+	}
+
+	// sem holds one token per running request; a nil channel means no limit.
+	var sem chan struct{}
+	if opts.MaxConcurrency > 0 && opts.MaxConcurrency < n {
+		sem = make(chan struct{}, opts.MaxConcurrency)
+	}
+
 	for i := 0; i < n; i++ {
 		wg.Add(1)
 		go func(index int) {
 			defer wg.Done()
 
-			streamCh, err := provider.GenerateCommitMessages(ctx, diff, opts)
-			if err != nil {
-				ch <- IndexedMessageEvent{Index: index, Err: err}
-				return
-			}
-
-			var buf strings.Builder
-			for chunk := range streamCh {
-				if chunk.Err != nil {
-					ch <- IndexedMessageEvent{Index: index, Err: chunk.Err}
-					return
-				}
-
-				if chunk.Done {
-					msg := parseMessage(buf.String())
-					if msg == "" {
-						ch <- IndexedMessageEvent{Index: index, Err: context.Canceled}
-						return
-					}
-					ch <- IndexedMessageEvent{
-						Index:   index,
-						Content: msg,
-						Done:    true,
-					}
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					ch <- IndexedMessageEvent{Index: index, Err: ctx.Err()}
 					return
 				}
-
-				if chunk.Content != "" {
-					buf.WriteString(chunk.Content)
-					ch <- IndexedMessageEvent{
-						Index: index,
-						Delta: chunk.Content,
-					}
-				}
-			}
-
-			// Defensive fallback: if provider closes without an explicit Done marker.
-			msg := parseMessage(buf.String())
-			if msg == "" {
-				ch <- IndexedMessageEvent{Index: index, Err: context.Canceled}
-				return
-			}
-			ch <- IndexedMessageEvent{
-				Index:   index,
-				Content: msg,
-				Done:    true,
 			}
+			generateSlot(ctx, provider, diff, opts, index, ch)
 		}(i)
 	}
 
@@ -114,3 +224,98 @@ func GenerateMultiple(ctx context.Context, provider Provider, diff string, opts
 
 	return ch
 }
+
+// generateSlot runs one request and reports its deltas and terminal event.
+func generateSlot(ctx context.Context, provider Provider, diff string, opts GenerateOptions, index int, ch chan<- IndexedMessageEvent) {
+	start := time.Now()
+
+	slotCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if opts.RequestTimeout > 0 {
+		timer := time.AfterFunc(opts.RequestTimeout, func() {
+			cancel(fmt.Errorf("%w after %s", ErrRequestTimeout, opts.RequestTimeout))
+		})
+		defer timer.Stop()
+	}
+	var firstToken *time.Timer
+	if opts.FirstTokenTimeout > 0 {
+		firstToken = time.AfterFunc(opts.FirstTokenTimeout, func() {
+			cancel(fmt.Errorf("%w (%s)", ErrFirstTokenTimeout, opts.FirstTokenTimeout))
+		})
+		defer firstToken.Stop()
+	}
+
+	fail := func(raw string, err error) {
+		// Prefer the timeout cause over the transport error it triggered.
+		if cause := context.Cause(slotCtx); cause != nil && ctx.Err() == nil {
+			err = cause
+		}
+		logStreamResult(index, raw, "", start, err)
+		ch <- IndexedMessageEvent{Index: index, Err: err}
+	}
+
+	opts.Slot = index
+	streamCh, err := provider.GenerateCommitMessages(slotCtx, diff, opts)
+	if err != nil {
+		fail("", err)
+		return
+	}
+
+	var buf strings.Builder
+	var usage *Usage
+	offline := false
+stream:
+	for {
+		select {
+		case <-slotCtx.Done():
+			// Let the provider goroutine finish its final send.
+			go func() {
+				for range streamCh {
+				}
+			}()
+			fail(buf.String(), context.Cause(slotCtx))
+			return
+		case chunk, ok := <-streamCh:
+			if !ok {
+				// Providers send an explicit Done marker; a closed channel
+				// without one is treated the same way.
+				break stream
+			}
+			if chunk.Err != nil {
+				fail(buf.String(), chunk.Err)
+				return
+			}
+			offline = offline || chunk.Offline
+			if chunk.Done {
+				usage = chunk.Usage
+				break stream
+			}
+			if chunk.Content != "" {
+				if firstToken != nil {
+					firstToken.Stop()
+				}
+				buf.WriteString(chunk.Content)
+				ch <- IndexedMessageEvent{
+					Index: index,
+					Delta: chunk.Content,
+				}
+			}
+		}
+	}
+
+	suggestion, ok := parseSuggestion(buf.String(), opts.Structured)
+	if !ok {
+		logStreamResult(index, buf.String(), "", start, ErrEmptyResponse)
+		ch <- IndexedMessageEvent{Index: index, Err: ErrEmptyResponse}
+		return
+	}
+	suggestion.Offline = offline
+	logStreamResult(index, buf.String(), suggestion.Message(), start, nil)
+	logUsage(index, usage)
+	ch <- IndexedMessageEvent{
+		Index:      index,
+		Suggestion: suggestion,
+		Usage:      usage,
+		Done:       true,
+	}
+}
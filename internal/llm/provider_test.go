@@ -0,0 +1,156 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+type stubProvider struct{}
+
+func (stubProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	close(ch)
+	return ch, nil
+}
+
+type stubListerProvider struct {
+	stubProvider
+	models []string
+}
+
+func (p stubListerProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.models, nil
+}
+
+func TestListModelsSortsAndDeduplicates(t *testing.T) {
+	p := stubListerProvider{models: []string{"gpt-b", " gpt-a ", "gpt-b", ""}}
+
+	got, err := ListModels(context.Background(), p)
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	want := []string{"gpt-a", "gpt-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListModels() = %v, want %v", got, want)
+	}
+}
+
+func TestListModelsUnsupported(t *testing.T) {
+	_, err := ListModels(context.Background(), stubProvider{})
+	if !errors.Is(err, ErrListModelsUnsupported) {
+		t.Fatalf("ListModels() error = %v, want ErrListModelsUnsupported", err)
+	}
+}
+
+// hangingProvider never streams anything until its context is canceled.
+type hangingProvider struct{}
+
+func (hangingProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		<-ctx.Done()
+		ch <- StreamChunk{Err: ctx.Err()}
+	}()
+	return ch, nil
+}
+
+func TestGenerateMultipleFirstTokenTimeout(t *testing.T) {
+	opts := GenerateOptions{FirstTokenTimeout: 20 * time.Millisecond}
+	var failed int
+	for ev := range GenerateMultiple(context.Background(), hangingProvider{}, "diff", opts, 2) {
+		if !errors.Is(ev.Err, ErrFirstTokenTimeout) {
+			t.Fatalf("event error = %v, want ErrFirstTokenTimeout", ev.Err)
+		}
+		failed++
+	}
+	if failed != 2 {
+		t.Fatalf("failed slots = %d, want 2", failed)
+	}
+}
+
+// countingProvider records the peak number of concurrent requests.
+type countingProvider struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (p *countingProvider) GenerateCommitMessages(ctx context.Context, diff string, opts GenerateOptions) (<-chan StreamChunk, error) {
+	p.mu.Lock()
+	p.running++
+	p.peak = max(p.peak, p.running)
+	p.mu.Unlock()
+
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		time.Sleep(10 * time.Millisecond)
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+		ch <- StreamChunk{Content: "feat: add thing"}
+		ch <- StreamChunk{Done: true}
+	}()
+	return ch, nil
+}
+
+func TestGenerateMultipleMaxConcurrency(t *testing.T) {
+	p := &countingProvider{}
+	var done int
+	for ev := range GenerateMultiple(context.Background(), p, "diff", GenerateOptions{MaxConcurrency: 2}, 5) {
+		if ev.Err != nil {
+			t.Fatalf("unexpected error: %v", ev.Err)
+		}
+		if ev.Done {
+			done++
+		}
+	}
+	if done != 5 {
+		t.Fatalf("done = %d, want 5", done)
+	}
+	if p.peak > 2 {
+		t.Fatalf("peak concurrency = %d, want <= 2", p.peak)
+	}
+}
+
+func TestProvidersSendMessageHistory(t *testing.T) {
+	opts := GenerateOptions{Messages: RefinementMessages("feat(api): add endpoint", "scope is http")}
+
+	chat := openAIMessages("diff", opts)
+	if len(chat) != 4 || chat[2].OfAssistant == nil || chat[3].OfUser == nil {
+		t.Fatalf("openAIMessages() = %+v, want system, diff, assistant, user", chat)
+	}
+	if got := chat[3].OfUser.Content.OfString.Value; !strings.Contains(got, "scope is http") {
+		t.Fatalf("feedback turn = %q", got)
+	}
+
+	claude := anthropicMessages("diff", anthropic.TextBlockParam{Text: "prefix"}, opts)
+	if len(claude) != 3 || claude[1].Role != anthropic.MessageParamRoleAssistant || claude[2].Role != anthropic.MessageParamRoleUser {
+		t.Fatalf("anthropicMessages() = %+v, want diff, assistant, user", claude)
+	}
+	if got := claude[2].Content[0].OfText.Text; !strings.Contains(got, "scope is http") {
+		t.Fatalf("feedback turn = %q", got)
+	}
+
+	// The heuristic provider cannot take feedback into account.
+	diff := "diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-a\n+b\n"
+	var got []string
+	for _, o := range []GenerateOptions{{}, opts} {
+		ch, err := NewHeuristicProvider().GenerateCommitMessages(context.Background(), diff, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, (<-ch).Content)
+	}
+	if got[0] != got[1] {
+		t.Fatalf("heuristic answer changed with history: %q vs %q", got[0], got[1])
+	}
+}
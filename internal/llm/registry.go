@@ -2,8 +2,12 @@ package llm
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/lieyanc/fire-commit/internal/config"
+	openaioption "github.com/openai/openai-go/v3/option"
 )
 
 // Known provider base URLs for OpenAI-compatible services.
@@ -24,7 +28,7 @@ var defaultModels = map[string]string{
 
 // ProviderNames returns the list of supported provider names.
 func ProviderNames() []string {
-	return []string{"openai", "anthropic", "gemini", "cerebras", "siliconflow", "custom"}
+	return []string{"openai", "anthropic", "gemini", "cerebras", "siliconflow", "custom", "exec", "heuristic"}
 }
 
 // ProviderDisplayNames returns human-readable names for providers.
@@ -36,10 +40,110 @@ func ProviderDisplayNames() map[string]string {
 		"cerebras":    "Cerebras",
 		"siliconflow": "SiliconFlow",
 		"custom":      "Custom (OpenAI-compatible)",
+		"exec":        "External command",
+		"heuristic":   "Offline heuristic (no API key)",
 	}
 }
 
-// DefaultModel returns the default model for a given provider.
+// Provider types accepted in a provider's `type:` field. Built-in provider
+// names imply their type, so `type:` is only needed for additional named
+// entries such as a vLLM box or OpenRouter.
+const (
+	TypeOpenAI       = "openai"
+	TypeAnthropic    = "anthropic"
+	TypeOpenAICompat = "openai_compat"
+	TypeOllama       = "ollama"
+	TypeExec         = "exec"
+	TypeHeuristic    = "heuristic"
+)
+
+// ProviderTypes returns the types a named provider entry may use.
+func ProviderTypes() []string {
+	return []string{TypeOpenAICompat, TypeOpenAI, TypeAnthropic, TypeOllama, TypeExec}
+}
+
+// ProviderTypeDisplayNames returns human-readable names for provider types.
+func ProviderTypeDisplayNames() map[string]string {
+	return map[string]string{
+		TypeOpenAICompat: "OpenAI-compatible (vLLM, OpenRouter, LiteLLM...)",
+		TypeOpenAI:       "OpenAI",
+		TypeAnthropic:    "Anthropic",
+		TypeOllama:       "Ollama",
+		TypeExec:         "External command",
+		TypeHeuristic:    "Offline heuristic",
+	}
+}
+
+// builtinTypes maps built-in provider names to their implied type.
+var builtinTypes = map[string]string{
+	"openai":      TypeOpenAI,
+	"anthropic":   TypeAnthropic,
+	"gemini":      TypeOpenAICompat,
+	"cerebras":    TypeOpenAICompat,
+	"siliconflow": TypeOpenAICompat,
+	"custom":      TypeOpenAICompat,
+	"exec":        TypeExec,
+	"heuristic":   TypeHeuristic,
+}
+
+// defaultOllamaBaseURL is Ollama's OpenAI-compatible endpoint on a local install.
+const defaultOllamaBaseURL = "http://localhost:11434/v1"
+
+// IsBuiltinProvider reports whether name is one of ProviderNames.
+func IsBuiltinProvider(name string) bool {
+	_, ok := builtinTypes[name]
+	return ok
+}
+
+// ProviderType returns the type of a provider entry: its `type:` field, or
+// the type implied by a built-in name. It is empty for unknown entries.
+func ProviderType(name string, provCfg config.ProviderConfig) string {
+	if provCfg.Type != "" {
+		return provCfg.Type
+	}
+	return builtinTypes[name]
+}
+
+// ConfiguredProviderNames returns the built-in provider names followed by
+// any additional named entries in cfg, sorted.
+func ConfiguredProviderNames(cfg *config.Config) []string {
+	names := ProviderNames()
+	var extra []string
+	for name := range cfg.Providers {
+		if !IsBuiltinProvider(name) {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return append(names, extra...)
+}
+
+// ProviderDisplayName returns the display name for a built-in provider, or
+// the entry name with its type for additional named entries.
+func ProviderDisplayName(name string, provCfg config.ProviderConfig) string {
+	if d, ok := ProviderDisplayNames()[name]; ok && provCfg.Type == "" {
+		return d
+	}
+	if typ := ProviderType(name, provCfg); typ != "" {
+		return fmt.Sprintf("%s (%s)", name, typ)
+	}
+	return name
+}
+
+// RequiresAPIKey reports whether a provider of the given type needs credentials.
+// Built-in provider names are accepted as well.
+func RequiresAPIKey(providerType string) bool {
+	if t, ok := builtinTypes[providerType]; ok {
+		providerType = t
+	}
+	switch providerType {
+	case TypeHeuristic, TypeExec, TypeOllama:
+		return false
+	}
+	return true
+}
+
+// DefaultModel returns the default model for a given provider name or type.
 func DefaultModel(provider string) string {
 	if m, ok := defaultModels[provider]; ok {
 		return m
@@ -51,11 +155,55 @@ func DefaultModel(provider string) string {
 func NewProvider(cfg *config.Config) (Provider, error) {
 	name := cfg.DefaultProvider
 	provCfg, ok := cfg.Providers[name]
-	if !ok {
+	typ := ProviderType(name, provCfg)
+	if !ok && typ != TypeHeuristic {
 		return nil, fmt.Errorf("provider %q not configured", name)
 	}
+	provider, err := NewNamedProvider(name, provCfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Generation.OfflineFallback && typ != TypeHeuristic && typ != TypeExec {
+		return NewFallbackProvider(provider, NewHeuristicProvider()), nil
+	}
+	return provider, nil
+}
+
+// NewNamedProvider creates a Provider for the named provider using provCfg,
+// independent of which provider is configured as the default.
+func NewNamedProvider(name string, provCfg config.ProviderConfig) (Provider, error) {
+	return newNamedProvider(name, provCfg, true)
+}
+
+// NewListingProvider is like NewNamedProvider but does not require a model,
+// so it can list the models available before one is chosen.
+func NewListingProvider(name string, provCfg config.ProviderConfig) (Provider, error) {
+	return newNamedProvider(name, provCfg, false)
+}
+
+func newNamedProvider(name string, provCfg config.ProviderConfig, requireModel bool) (Provider, error) {
+	typ := ProviderType(name, provCfg)
+	switch typ {
+	case "":
+		return nil, fmt.Errorf("unknown provider: %q (set type: to one of %s)", name, strings.Join(ProviderTypes(), ", "))
+	case TypeHeuristic:
+		return NewHeuristicProvider(), nil
+	case TypeExec:
+		return newExecProviderFromConfig(name, provCfg)
+	}
 
-	if provCfg.APIKey == "" {
+	var apiKey string
+	switch {
+	case provCfg.APIKeySource() != config.KeySourceNone:
+		key, err := provCfg.ResolveAPIKey()
+		if err != nil {
+			return nil, fmt.Errorf("API key for provider %q: %w", name, err)
+		}
+		apiKey = key
+	case typ == TypeOllama:
+		// Ollama ignores the key, but the SDK requires a non-empty one.
+		apiKey = "ollama"
+	default:
 		return nil, fmt.Errorf("API key not set for provider %q", name)
 	}
 
@@ -63,21 +211,70 @@ func NewProvider(cfg *config.Config) (Provider, error) {
 	if model == "" {
 		model = DefaultModel(name)
 	}
+	if model == "" {
+		model = DefaultModel(typ)
+	}
+	if model == "" && typ == TypeOllama && requireModel {
+		// There is no default: the model depends on what was pulled.
+		return nil, fmt.Errorf("provider %q has no model set; pick one with 'firecommit models --provider %s' and set model: in the config", name, name)
+	}
+
+	baseURL := provCfg.BaseURL
+	if baseURL == "" {
+		baseURL = providerBaseURLs[name]
+	}
+
+	switch typ {
+	case TypeAnthropic:
+		opts, err := anthropicClientOptions(provCfg.Transport)
+		if err != nil {
+			return nil, fmt.Errorf("provider %q transport: %w", name, err)
+		}
+		if baseURL != "" {
+			opts = append(opts, anthropicoption.WithBaseURL(baseURL))
+		}
+		return NewAnthropicProvider(apiKey, model, opts...), nil
+	}
+
+	opts, err := openAIClientOptions(provCfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("provider %q transport: %w", name, err)
+	}
 
-	switch name {
-	case "openai":
-		return NewOpenAIProvider(provCfg.APIKey, model), nil
-	case "anthropic":
-		return NewAnthropicProvider(provCfg.APIKey, model), nil
-	case "gemini", "cerebras", "siliconflow":
-		baseURL := providerBaseURLs[name]
-		return NewOpenAICompatProvider(provCfg.APIKey, model, baseURL), nil
-	case "custom":
-		if provCfg.BaseURL == "" {
-			return nil, fmt.Errorf("custom provider requires a base_url")
+	switch typ {
+	case TypeOpenAI:
+		if baseURL != "" {
+			opts = append(opts, openaioption.WithBaseURL(baseURL))
 		}
-		return NewOpenAICompatProvider(provCfg.APIKey, model, provCfg.BaseURL), nil
+		return NewOpenAIProvider(apiKey, model, opts...), nil
+	case TypeOllama:
+		if baseURL == "" {
+			baseURL = defaultOllamaBaseURL
+		}
+		return NewOpenAICompatProvider(apiKey, model, baseURL, opts...), nil
+	case TypeOpenAICompat:
+		if baseURL == "" {
+			return nil, fmt.Errorf("provider %q requires a base_url", name)
+		}
+		return NewOpenAICompatProvider(apiKey, model, baseURL, opts...), nil
 	default:
-		return nil, fmt.Errorf("unknown provider: %q", name)
+		return nil, fmt.Errorf("provider %q has unknown type %q", name, typ)
+	}
+}
+
+// newExecProviderFromConfig builds an ExecProvider. The API key is optional
+// and only resolved when a source is configured.
+func newExecProviderFromConfig(name string, provCfg config.ProviderConfig) (Provider, error) {
+	if provCfg.Command == "" {
+		return nil, fmt.Errorf("provider %q requires a command", name)
+	}
+	var apiKey string
+	if provCfg.APIKeySource() != config.KeySourceNone {
+		key, err := provCfg.ResolveAPIKey()
+		if err != nil {
+			return nil, fmt.Errorf("API key for provider %q: %w", name, err)
+		}
+		apiKey = key
 	}
+	return NewExecProvider(provCfg.Command, provCfg.Model, apiKey), nil
 }
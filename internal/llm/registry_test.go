@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lieyanc/fire-commit/internal/config"
+)
+
+func TestNewNamedProviderTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		provCfg config.ProviderConfig
+		want    string
+		wantErr string
+	}{
+		{"openai", config.ProviderConfig{APIKey: "k"}, "*llm.OpenAIProvider", ""},
+		{"gemini", config.ProviderConfig{APIKey: "k"}, "*llm.OpenAICompatProvider", ""},
+		{"vllm", config.ProviderConfig{Type: TypeOpenAICompat, APIKey: "k", BaseURL: "http://gpu-box:8000/v1"}, "*llm.OpenAICompatProvider", ""},
+		{"openrouter", config.ProviderConfig{Type: TypeOpenAICompat, APIKey: "k"}, "", "requires a base_url"},
+		{"claude-proxy", config.ProviderConfig{Type: TypeAnthropic, APIKey: "k", BaseURL: "https://proxy.example.com"}, "*llm.AnthropicProvider", ""},
+		{"local", config.ProviderConfig{Type: TypeOllama, Model: "qwen3"}, "*llm.OpenAICompatProvider", ""},
+		{"gpu-box", config.ProviderConfig{Type: TypeOllama}, "", "has no model set"},
+		{"gateway", config.ProviderConfig{Type: TypeExec, Command: "gateway"}, "*llm.ExecProvider", ""},
+		{"mystery", config.ProviderConfig{APIKey: "k"}, "", "unknown provider"},
+		{"work", config.ProviderConfig{Type: TypeOpenAI}, "", "API key not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewNamedProvider(tt.name, tt.provCfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewNamedProvider() error = %v", err)
+			}
+			if got := reflect.TypeOf(p).String(); got != tt.want {
+				t.Fatalf("provider type = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListingProviderDoesNotRequireModel(t *testing.T) {
+	p, err := NewListingProvider("local", config.ProviderConfig{Type: TypeOllama})
+	if err != nil {
+		t.Fatalf("NewListingProvider() error = %v", err)
+	}
+	if _, ok := p.(ModelLister); !ok {
+		t.Fatalf("%T cannot list models", p)
+	}
+}
+
+func TestConfiguredProviderNames(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Providers["vllm"] = config.ProviderConfig{Type: TypeOpenAICompat}
+	cfg.Providers["openrouter"] = config.ProviderConfig{Type: TypeOpenAICompat}
+	cfg.Providers["openai"] = config.ProviderConfig{}
+
+	names := ConfiguredProviderNames(cfg)
+	builtins := ProviderNames()
+	if !reflect.DeepEqual(names[:len(builtins)], builtins) {
+		t.Fatalf("names = %v, want built-ins first", names)
+	}
+	if got := names[len(builtins):]; !reflect.DeepEqual(got, []string{"openrouter", "vllm"}) {
+		t.Fatalf("extra names = %v", got)
+	}
+}
@@ -0,0 +1,198 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SplitHunk is one unit offered to the model when splitting staged changes:
+// a single hunk, or a whole file when it cannot be split.
+type SplitHunk struct {
+	Path string
+	// Patch is the file header followed by the hunk.
+	Patch string
+}
+
+// SplitGroup is one proposed commit: a message and the 0-based indexes of
+// its hunks.
+type SplitGroup struct {
+	Message string
+	Hunks   []int
+}
+
+const splitInstructions = `The diff below is split into numbered hunks, each introduced by a "### Hunk N: <path>" line.
+Group the hunks into logical, self-contained commits and write one commit message per group.
+
+Apply the system rubric and writing rules to each message, but instead of a raw line
+respond with a single JSON object and nothing else:
+{"commits": [{"message": "<commit message>", "hunks": [<hunk numbers>]}]}
+
+Rules:
+- Every hunk belongs to exactly one commit
+- Order commits so that each one builds on the previous ones
+- Keep hunks of one file together unless they serve clearly different purposes
+- Prefer fewer commits; never split one coherent change`
+
+// FormatSplitHunks renders hunks as the numbered listing sent as the diff
+// with GenerateOptions.Split.
+func FormatSplitHunks(hunks []SplitHunk) string {
+	var b strings.Builder
+	for i, h := range hunks {
+		fmt.Fprintf(&b, "### Hunk %d: %s\n", i+1, h.Path)
+		b.WriteString(strings.TrimSuffix(h.Patch, "\n"))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+var splitHunkPattern = regexp.MustCompile(`(?m)^### Hunk (\d+): (.*)$`)
+
+// parseSplitHunks is the inverse of FormatSplitHunks.
+func parseSplitHunks(listing string) []SplitHunk {
+	matches := splitHunkPattern.FindAllStringSubmatchIndex(listing, -1)
+	hunks := make([]SplitHunk, 0, len(matches))
+	for i, m := range matches {
+		end := len(listing)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		hunks = append(hunks, SplitHunk{
+			Path:  listing[m[4]:m[5]],
+			Patch: strings.TrimSpace(listing[m[1]:end]),
+		})
+	}
+	return hunks
+}
+
+// GenerateSplit asks the provider to group n hunks, formatted with
+// FormatSplitHunks, into commits. Hunks the model leaves out or refers to
+// twice are dropped from all but their first group; the caller decides what
+// to do with unassigned hunks.
+func GenerateSplit(ctx context.Context, provider Provider, listing string, n int, opts GenerateOptions) ([]SplitGroup, error) {
+	opts.Split = true
+	opts.Structured = false
+	opts.Messages = nil
+
+	var raw strings.Builder
+	for ev := range GenerateMultiple(ctx, provider, listing, opts, 1) {
+		switch {
+		case ev.Err != nil:
+			return nil, ev.Err
+		case ev.Delta != "":
+			raw.WriteString(ev.Delta)
+		}
+	}
+	return parseSplitPlan(raw.String(), n)
+}
+
+// parseSplitPlan reads the model's JSON plan. Hunk numbers are 1-based in
+// the plan and 0-based in the result.
+func parseSplitPlan(raw string, n int) ([]SplitGroup, error) {
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("model did not return a commit plan")
+	}
+	var plan struct {
+		Commits []struct {
+			Message string `json:"message"`
+			Hunks   []int  `json:"hunks"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &plan); err != nil {
+		return nil, fmt.Errorf("model returned an invalid commit plan: %w", err)
+	}
+
+	assigned := make([]bool, n)
+	var groups []SplitGroup
+	for _, c := range plan.Commits {
+		g := SplitGroup{Message: strings.TrimSpace(c.Message)}
+		for _, h := range c.Hunks {
+			if h < 1 || h > n || assigned[h-1] {
+				continue
+			}
+			assigned[h-1] = true
+			g.Hunks = append(g.Hunks, h-1)
+		}
+		if len(g.Hunks) > 0 {
+			sort.Ints(g.Hunks)
+			groups = append(groups, g)
+		}
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("model returned an empty commit plan")
+	}
+	return groups, nil
+}
+
+// heuristicSplit groups hunks by kind (tests, docs, CI, dependencies) and
+// otherwise by directory, and returns the plan as JSON like a model would.
+func heuristicSplit(listing string) string {
+	hunks := parseSplitHunks(listing)
+
+	var keys []string
+	members := make(map[string][]int)
+	patches := make(map[string][]string)
+	for i, h := range hunks {
+		key := splitGroupKey(h)
+		if _, ok := members[key]; !ok {
+			keys = append(keys, key)
+		}
+		members[key] = append(members[key], i+1)
+		patches[key] = append(patches[key], h.Patch)
+	}
+
+	type commit struct {
+		Message string `json:"message"`
+		Hunks   []int  `json:"hunks"`
+	}
+	plan := struct {
+		Commits []commit `json:"commits"`
+	}{}
+	for _, key := range keys {
+		plan.Commits = append(plan.Commits, commit{
+			Message: heuristicMessage(strings.Join(patches[key], "\n")),
+			Hunks:   members[key],
+		})
+	}
+	out, _ := json.Marshal(plan)
+	return string(out)
+}
+
+// splitGroupKey returns the heuristic group of a hunk.
+func splitGroupKey(h SplitHunk) string {
+	f := diffFile{path: h.Path}
+	switch {
+	case isTestFile(f):
+		return "test"
+	case isDocFile(f):
+		return "docs"
+	case isCIFile(f):
+		return "ci"
+	case isDepFile(f):
+		return "deps"
+	}
+	parts := strings.Split(h.Path, "/")
+	switch {
+	case len(parts) == 1:
+		return "root"
+	case len(parts) > 2 && isLayoutDir(parts[0]):
+		return "dir:" + parts[0] + "/" + parts[1]
+	default:
+		return "dir:" + parts[0]
+	}
+}
+
+// isLayoutDir reports whether dir is a generic top-level layout directory
+// whose children are the meaningful components.
+func isLayoutDir(dir string) bool {
+	switch dir {
+	case "internal", "cmd", "pkg", "src", "lib", "app":
+		return true
+	}
+	return false
+}
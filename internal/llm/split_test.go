@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSplitPlan(t *testing.T) {
+	raw := "```json\n" + `{"commits": [
+  {"message": "feat(api): add endpoint", "hunks": [3, 1]},
+  {"message": "docs: describe endpoint", "hunks": [1, 2, 9]},
+  {"message": "chore: nothing left", "hunks": [3]}
+]}` + "\n```"
+	got, err := parseSplitPlan(raw, 3)
+	if err != nil {
+		t.Fatalf("parseSplitPlan() error = %v", err)
+	}
+	want := []SplitGroup{
+		{Message: "feat(api): add endpoint", Hunks: []int{0, 2}},
+		{Message: "docs: describe endpoint", Hunks: []int{1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseSplitPlan() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSplitPlanRejectsGarbage(t *testing.T) {
+	for _, raw := range []string{"", "feat: add endpoint", `{"commits": []}`, `{"commits": [{"hunks": [7]}]}`} {
+		if _, err := parseSplitPlan(raw, 2); err == nil {
+			t.Errorf("parseSplitPlan(%q) expected error", raw)
+		}
+	}
+}
+
+func TestGenerateSplitHeuristic(t *testing.T) {
+	hunks := []SplitHunk{
+		{Path: "internal/api/handler.go", Patch: "diff --git a/internal/api/handler.go b/internal/api/handler.go\n@@ -1 +1,2 @@\n+func Serve() {}"},
+		{Path: "README.md", Patch: "diff --git a/README.md b/README.md\n@@ -1 +1 @@\n-old\n+new"},
+		{Path: "internal/api/routes.go", Patch: "diff --git a/internal/api/routes.go b/internal/api/routes.go\n@@ -1 +1 @@\n-a\n+b"},
+	}
+	listing := FormatSplitHunks(hunks)
+	if got := parseSplitHunks(listing); len(got) != 3 || got[1].Path != "README.md" || got[1].Patch != hunks[1].Patch {
+		t.Fatalf("parseSplitHunks() = %+v", got)
+	}
+
+	groups, err := GenerateSplit(context.Background(), NewHeuristicProvider(), listing, len(hunks), GenerateOptions{})
+	if err != nil {
+		t.Fatalf("GenerateSplit() error = %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("groups = %+v, want 2", groups)
+	}
+	if !reflect.DeepEqual(groups[0].Hunks, []int{0, 2}) || !strings.HasPrefix(groups[1].Message, "docs") {
+		t.Fatalf("groups = %+v", groups)
+	}
+}
+
+func TestSplitPromptAsksForPlan(t *testing.T) {
+	prefix := buildUserPromptPrefix(GenerateOptions{Split: true, Structured: true})
+	if !strings.Contains(prefix, `{"commits"`) || strings.Contains(prefix, `"rationale"`) {
+		t.Fatalf("split prefix should ask for a commit plan only, got %q", prefix)
+	}
+}
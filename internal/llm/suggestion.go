@@ -0,0 +1,194 @@
+package llm
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Suggestion is a parsed commit message suggestion.
+type Suggestion struct {
+	Type        string   `json:"type"`
+	Scope       string   `json:"scope"`
+	Breaking    bool     `json:"breaking"`
+	Description string   `json:"description"`
+	Body        string   `json:"body"`
+	Footers     []string `json:"footers"`
+	Rationale   string   `json:"rationale"`
+	// Offline is set when the offline heuristic wrote the suggestion because
+	// the configured provider could not be reached.
+	Offline bool `json:"-"`
+}
+
+// suggestionToolName names the OpenAI JSON schema and Anthropic tool used for
+// structured output.
+const suggestionToolName = "commit_suggestion"
+
+// suggestionSchemaProperties is the JSON schema for Suggestion's fields.
+var suggestionSchemaProperties = map[string]any{
+	"type": map[string]any{
+		"type": "string",
+		"enum": []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"},
+	},
+	"scope":       map[string]any{"type": "string", "description": "optional module/component, empty when none"},
+	"breaking":    map[string]any{"type": "boolean"},
+	"description": map[string]any{"type": "string", "description": "imperative summary without type/scope prefix"},
+	"body":        map[string]any{"type": "string", "description": "optional explanation of what and why, empty when none"},
+	"footers":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	"rationale":   map[string]any{"type": "string", "description": "one sentence on why this type and scope were chosen"},
+}
+
+var suggestionSchemaRequired = []string{"type", "scope", "breaking", "description", "body", "footers", "rationale"}
+
+// suggestionJSONSchema returns the full object schema for structured output.
+func suggestionJSONSchema() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"properties":           suggestionSchemaProperties,
+		"required":             suggestionSchemaRequired,
+		"additionalProperties": false,
+	}
+}
+
+// Header renders the Conventional Commits header line. A suggestion without a
+// type (e.g. a free-form fallback) renders as its description.
+func (s Suggestion) Header() string {
+	if s.Type == "" {
+		return s.Description
+	}
+	var b strings.Builder
+	b.WriteString(s.Type)
+	if s.Scope != "" {
+		b.WriteString("(" + s.Scope + ")")
+	}
+	if s.Breaking {
+		b.WriteString("!")
+	}
+	b.WriteString(": ")
+	b.WriteString(s.Description)
+	return b.String()
+}
+
+// Message renders the full commit message: header, optional body and footers.
+func (s Suggestion) Message() string {
+	msg := s.Header()
+	if body := strings.TrimSpace(s.Body); body != "" {
+		msg += "\n\n" + body
+	}
+	var footers []string
+	for _, f := range s.Footers {
+		if f = strings.TrimSpace(f); f != "" {
+			footers = append(footers, f)
+		}
+	}
+	if len(footers) > 0 {
+		msg += "\n\n" + strings.Join(footers, "\n")
+	}
+	return msg
+}
+
+var headerPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+// ParseHeader splits a Conventional Commits header into a Suggestion. Lines
+// that do not follow the format become a description-only suggestion.
+func ParseHeader(header string) Suggestion {
+	header = strings.TrimSpace(header)
+	m := headerPattern.FindStringSubmatch(header)
+	if m == nil {
+		return Suggestion{Description: header}
+	}
+	return Suggestion{
+		Type:        strings.ToLower(m[1]),
+		Scope:       strings.TrimSpace(m[2]),
+		Breaking:    m[3] == "!",
+		Description: strings.TrimSpace(m[4]),
+	}
+}
+
+// parseSuggestion parses a model response. In structured mode it expects a
+// JSON object; malformed JSON falls back to parseMessage on the raw text.
+func parseSuggestion(raw string, structured bool) (Suggestion, bool) {
+	if structured {
+		if s, ok := parseJSONSuggestion(raw); ok {
+			return s, true
+		}
+	}
+	msg := parseMessage(raw)
+	if msg == "" {
+		return Suggestion{}, false
+	}
+	return ParseHeader(msg), true
+}
+
+func parseJSONSuggestion(raw string) (Suggestion, bool) {
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start < 0 || end <= start {
+		return Suggestion{}, false
+	}
+
+	var s Suggestion
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &s); err != nil {
+		return Suggestion{}, false
+	}
+	s.Type = strings.ToLower(strings.TrimSpace(s.Type))
+	s.Scope = strings.TrimSpace(s.Scope)
+	s.Description = strings.TrimSpace(s.Description)
+	if s.Type == "" || s.Description == "" {
+		return Suggestion{}, false
+	}
+	// Models sometimes repeat the prefix inside the description.
+	if p := ParseHeader(s.Description); p.Type == s.Type {
+		s.Description = p.Description
+	}
+	return s, true
+}
+
+var (
+	partialTypePattern  = regexp.MustCompile(`"type"\s*:\s*"([^"]*)"`)
+	partialScopePattern = regexp.MustCompile(`"scope"\s*:\s*"([^"]*)"`)
+	// The description may still be streaming, so its closing quote is
+	// optional.
+	partialDescriptionPattern = regexp.MustCompile(`"description"\s*:\s*"((?:[^"\\]|\\.)*)`)
+)
+
+// StreamPreview returns a readable preview of a response that is still
+// streaming. Structured responses are JSON, so the header is assembled from
+// the fields received so far; it is "" until the description starts. Plain
+// responses, and structured ones that did not start as JSON, are returned
+// unchanged.
+func StreamPreview(raw string, structured bool) string {
+	if !structured {
+		return raw
+	}
+	trimmed := strings.TrimSpace(raw)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "```") {
+		return raw
+	}
+	m := partialDescriptionPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return ""
+	}
+	var s Suggestion
+	s.Description = unquotePartial(m[1])
+	if m := partialTypePattern.FindStringSubmatch(raw); m != nil {
+		s.Type = m[1]
+	}
+	if m := partialScopePattern.FindStringSubmatch(raw); m != nil {
+		s.Scope = m[1]
+	}
+	return s.Header()
+}
+
+// unquotePartial decodes the escapes of a JSON string body that may be cut
+// off in the middle of an escape sequence.
+func unquotePartial(body string) string {
+	for body != "" {
+		var out string
+		if err := json.Unmarshal([]byte(`"`+body+`"`), &out); err == nil {
+			return out
+		}
+		body = body[:len(body)-1]
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/debuglog"
+	openaioption "github.com/openai/openai-go/v3/option"
+)
+
+// newHTTPClient builds an *http.Client honoring the provider's transport
+// settings. It returns nil when no settings are configured so the SDK keeps
+// its own default client.
+func newHTTPClient(t config.TransportConfig) (*http.Client, error) {
+	if t.Proxy == "" && t.CACertFile == "" && !t.InsecureSkipVerify && t.ConnectTimeout == 0 && t.Timeout == 0 {
+		return nil, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if t.Proxy != "" {
+		proxyURL, err := url.Parse(t.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", t.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if t.CACertFile != "" || t.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify}
+		if t.CACertFile != "" {
+			pem, err := os.ReadFile(t.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("read CA certificate: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", t.CACertFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if t.ConnectTimeout > 0 {
+		dialer := &net.Dialer{Timeout: t.ConnectTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = t.ConnectTimeout
+	}
+
+	return &http.Client{Transport: transport, Timeout: t.Timeout}, nil
+}
+
+// sortedHeaderKeys returns header names in a stable order.
+func sortedHeaderKeys(headers map[string]string) []string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// openAIClientOptions converts transport settings into openai-go request options.
+func openAIClientOptions(t config.TransportConfig) ([]openaioption.RequestOption, error) {
+	httpClient, err := newHTTPClient(t)
+	if err != nil {
+		return nil, err
+	}
+	var opts []openaioption.RequestOption
+	if httpClient != nil {
+		opts = append(opts, openaioption.WithHTTPClient(httpClient))
+	}
+	for _, k := range sortedHeaderKeys(t.Headers) {
+		opts = append(opts, openaioption.WithHeader(k, t.Headers[k]))
+	}
+	if debuglog.Enabled() {
+		opts = append(opts, openaioption.WithMiddleware(debugMiddleware))
+	}
+	return opts, nil
+}
+
+// anthropicClientOptions converts transport settings into anthropic-sdk-go request options.
+func anthropicClientOptions(t config.TransportConfig) ([]anthropicoption.RequestOption, error) {
+	httpClient, err := newHTTPClient(t)
+	if err != nil {
+		return nil, err
+	}
+	var opts []anthropicoption.RequestOption
+	if httpClient != nil {
+		opts = append(opts, anthropicoption.WithHTTPClient(httpClient))
+	}
+	for _, k := range sortedHeaderKeys(t.Headers) {
+		opts = append(opts, anthropicoption.WithHeader(k, t.Headers[k]))
+	}
+	if debuglog.Enabled() {
+		opts = append(opts, anthropicoption.WithMiddleware(debugMiddleware))
+	}
+	return opts, nil
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lieyanc/fire-commit/internal/config"
+)
+
+func TestNewHTTPClientDefaultsToSDKClient(t *testing.T) {
+	client, err := newHTTPClient(config.TransportConfig{Headers: map[string]string{"X-Team-Id": "42"}})
+	if err != nil {
+		t.Fatalf("newHTTPClient() error = %v", err)
+	}
+	if client != nil {
+		t.Fatalf("expected nil client when only headers are set")
+	}
+}
+
+func TestNewHTTPClientAppliesSettings(t *testing.T) {
+	client, err := newHTTPClient(config.TransportConfig{
+		Proxy:              "http://proxy.internal:3128",
+		InsecureSkipVerify: true,
+		Timeout:            90 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("newHTTPClient() error = %v", err)
+	}
+	if client.Timeout != 90*time.Second {
+		t.Fatalf("timeout got %v", client.Timeout)
+	}
+	transport := client.Transport.(*http.Transport)
+	if !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Fatalf("expected InsecureSkipVerify")
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com", nil)
+	proxyURL, err := transport.Proxy(req)
+	if err != nil || proxyURL == nil || proxyURL.Host != "proxy.internal:3128" {
+		t.Fatalf("proxy got %v, %v", proxyURL, err)
+	}
+}
+
+func TestNewHTTPClientRejectsBadInputs(t *testing.T) {
+	if _, err := newHTTPClient(config.TransportConfig{Proxy: "::bad"}); err == nil {
+		t.Fatalf("expected error for invalid proxy")
+	}
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newHTTPClient(config.TransportConfig{CACertFile: path}); err == nil {
+		t.Fatalf("expected error for CA file without certificates")
+	}
+}
@@ -3,13 +3,16 @@ package tui
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
 	"github.com/lieyanc/fire-commit/internal/llm"
 )
 
@@ -23,33 +26,67 @@ const (
 	PhaseConfirm
 	PhaseCommitting
 	PhaseDone
+	// PhaseFiles lets the user choose the files to stage before generation.
+	PhaseFiles
+	// PhaseHunks stages individual hunks of one file.
+	PhaseHunks
 )
 
 // Model is the top-level bubbletea model.
 type Model struct {
 	phase Phase
 	cfg   *config.Config
+	repo  *git.Repo
 	diff  string
 	stat  string
 
 	// Loading: progressive per-message results
-	spinner    spinner.Model
-	messages   []string
-	partial    []string
-	slotDone   []bool
-	slotFailed []bool
-	completed  int
-	finished   int
-	failed     int
-	total      int
-	resultCh   <-chan llm.IndexedMessageEvent
+	spinner     spinner.Model
+	messages    []string
+	suggestions []llm.Suggestion
+	partial     []string
+	slotDone    []bool
+	slotFailed  []bool
+	slotErr     []error
+	completed   int
+	finished    int
+	failed      int
+	total       int
+	resultCh    <-chan llm.IndexedMessageEvent
+	usage       llm.Usage
 	// generationID identifies the active round of LLM generation.
 	// It prevents stale events from a previous round from mutating state.
 	generationID int
 
+	// Files
+	files      []fileEntry
+	fileCursor int
+	staging    bool
+	filesErr   error
+
+	// Hunks
+	hunkFile   int
+	hunkDiff   git.FileDiff
+	hunkOn     []bool
+	hunkCursor int
+	hunkScroll int
+
+	// Diff viewer, shown over the loading, select and confirm screens.
+	showDiff  bool
+	diffView  viewport.Model
+	diffFiles []diffFileEntry
+	// diffFile is the file the viewer is on.
+	diffFile int
+
 	// Select
 	cursor int
 
+	// Refine: feedback turns sent with every later generation round.
+	refineInput  textinput.Model
+	refining     bool
+	conversation []llm.Message
+	lastFeedback string
+
 	// Edit
 	editArea textarea.Model
 	editing  bool
@@ -62,6 +99,12 @@ type Model struct {
 	tagHintBase   string
 	tagHintMinor  string
 	tagHintPatch  string
+	// commitOpts holds the signing and hook toggles; trailers are the saved
+	// co-authors and reviewers that can be added.
+	commitOpts      git.CommitOptions
+	trailers        []trailerChoice
+	pickingTrailers bool
+	trailerCursor   int
 
 	// Result
 	committed  bool
@@ -83,6 +126,31 @@ type Model struct {
 
 	// Whether user chose to push
 	wantPush bool
+	// pushByDefault preselects "commit & push" (--push).
+	pushByDefault bool
+	// pushTarget is the current branch's upstream and remotes; pushRemote
+	// is the remote chosen for a branch without upstream.
+	pushTarget   git.PushTarget
+	pushRemote   string
+	pushOptions  []string
+	pushCh       <-chan tea.Msg
+	pushProgress string
+
+	// Sync with the upstream before pushing.
+	fetching    bool
+	syncing     bool
+	syncPrompt  bool
+	syncCursor  int
+	syncChoice  int
+	behind      int
+	synced      bool
+	syncErr     error
+	pushSkipped bool
+
+	// Amend: replace HEAD instead of creating a new commit (--amend).
+	amend           bool
+	previousMessage string
+	headPushed      bool
 }
 
 // messageReadyMsg is a streamed event for one LLM request.
@@ -90,7 +158,8 @@ type messageReadyMsg struct {
 	generationID int
 	index        int
 	delta        string
-	content      string
+	suggestion   llm.Suggestion
+	usage        *llm.Usage
 	done         bool
 	err          error
 }
@@ -101,6 +170,12 @@ type allDoneMsg struct{ generationID int }
 // commitDoneMsg signals the commit operation completed.
 type commitDoneMsg struct{ err error }
 
+// pushStartedMsg delivers the channel of a running push.
+type pushStartedMsg struct{ ch <-chan tea.Msg }
+
+// pushProgressMsg is one progress line printed by git push.
+type pushProgressMsg struct{ line string }
+
 // pushDoneMsg signals the push operation completed.
 type pushDoneMsg struct{ err error }
 
@@ -110,14 +185,14 @@ type tagDoneMsg struct{ err error }
 // tagPushDoneMsg signals the tag push completed.
 type tagPushDoneMsg struct{ err error }
 
-// NewModel creates a new TUI model.
-func NewModel(cfg *config.Config, diff, stat string) Model {
+// NewModel creates a new TUI model for repo.
+func NewModel(cfg *config.Config, repo *git.Repo, diff, stat string) Model {
 	s := newSpinner()
 
 	ta := textarea.New()
 	ta.Placeholder = "Edit commit message..."
 	ta.Focus()
-	ta.CharLimit = 500
+	ta.CharLimit = 2000
 	ta.SetWidth(60)
 	ta.SetHeight(5)
 
@@ -127,6 +202,10 @@ func NewModel(cfg *config.Config, diff, stat string) Model {
 	ti.CharLimit = 50
 	ti.Width = 30
 
+	ri := textinput.New()
+	ri.Placeholder = "e.g. mention the migration, scope should be api"
+	ri.CharLimit = 300
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	n := cfg.Generation.NumSuggestions
@@ -137,21 +216,27 @@ func NewModel(cfg *config.Config, diff, stat string) Model {
 	model := Model{
 		phase:         PhaseLoading,
 		cfg:           cfg,
+		repo:          repo,
 		diff:          diff,
 		stat:          stat,
 		spinner:       s,
 		messages:      make([]string, 0, n),
+		suggestions:   make([]llm.Suggestion, 0, n),
 		partial:       make([]string, n),
 		slotDone:      make([]bool, n),
 		slotFailed:    make([]bool, n),
+		slotErr:       make([]error, n),
 		total:         n,
 		generationID:  1,
 		editArea:      ta,
 		tagInput:      ti,
+		refineInput:   ri,
 		tagHintBase:   initialTagHints.base,
 		tagHintMinor:  initialTagHints.minor,
 		tagHintPatch:  initialTagHints.patch,
 		confirmCursor: confirmCommitOnly,
+		commitOpts:    CommitOptions(cfg),
+		trailers:      trailerChoices(cfg),
 		ctx:           ctx,
 		cancel:        cancel,
 	}
@@ -168,6 +253,9 @@ func newSpinner() spinner.Model {
 }
 
 func (m Model) Init() tea.Cmd {
+	if m.phase == PhaseFiles {
+		return m.spinner.Tick
+	}
 	return tea.Batch(m.spinner.Tick, m.startGeneration())
 }
 
@@ -218,7 +306,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.showDiff {
+		return m.updateDiff(msg)
+	}
+
 	switch m.phase {
+	case PhaseFiles:
+		return m.updateFiles(msg)
+	case PhaseHunks:
+		return m.updateHunks(msg)
 	case PhaseLoading:
 		return m.updateLoading(msg)
 	case PhaseSelect:
@@ -237,7 +333,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	if m.showDiff {
+		return m.viewDiff()
+	}
 	switch m.phase {
+	case PhaseFiles:
+		return m.viewFiles()
+	case PhaseHunks:
+		return m.viewHunks()
 	case PhaseLoading:
 		return m.viewLoading()
 	case PhaseSelect:
@@ -265,9 +368,9 @@ func (m Model) startGeneration() tea.Cmd {
 			}
 		}
 
-		opts := llm.GenerateOptions{
-			Language: m.cfg.Generation.Language,
-		}
+		opts := GenerateOptions(m.cfg, m.repo)
+		opts.Messages = m.conversation
+		opts.PreviousMessage = m.previousMessage
 
 		ch := llm.GenerateMultiple(m.ctx, provider, m.diff, opts, m.total)
 		return startResultsMsg{
@@ -277,6 +380,31 @@ func (m Model) startGeneration() tea.Cmd {
 	}
 }
 
+// languageSampleSize is how many recent subjects are inspected when the
+// language is set to auto.
+const languageSampleSize = 30
+
+// GenerateOptions builds the generation options for cfg, reading the history
+// of repo for examples and automatic language detection when enabled.
+func GenerateOptions(cfg *config.Config, repo *git.Repo) llm.GenerateOptions {
+	opts := llm.GenerateOptions{
+		Language:          cfg.Generation.Language,
+		Structured:        cfg.Generation.StructuredOutput,
+		Convention:        cfg.Generation.Convention,
+		MaxConcurrency:    cfg.Generation.MaxConcurrency,
+		RequestTimeout:    cfg.Generation.RequestTimeout,
+		FirstTokenTimeout: cfg.Generation.FirstTokenTimeout,
+	}
+	if n := cfg.Generation.HistoryExamples; n > 0 {
+		opts.History, _ = repo.RecentSubjects(n)
+	}
+	if opts.Language == llm.LanguageAuto {
+		subjects, _ := repo.RecentSubjects(languageSampleSize)
+		opts.Language = llm.ResolveLanguage(opts.Language, subjects)
+	}
+	return opts
+}
+
 // startResultsMsg delivers the message-event channel to the model.
 type startResultsMsg struct {
 	generationID int
@@ -294,7 +422,8 @@ func waitForMessage(ch <-chan llm.IndexedMessageEvent, generationID int) tea.Cmd
 			generationID: generationID,
 			index:        msg.Index,
 			delta:        msg.Delta,
-			content:      msg.Content,
+			suggestion:   msg.Suggestion,
+			usage:        msg.Usage,
 			done:         msg.Done,
 			err:          msg.Err,
 		}
@@ -324,6 +453,7 @@ func (m Model) handleGenerationMessage(msg messageReadyMsg) (tea.Model, tea.Cmd)
 	if msg.err != nil {
 		m.slotDone[msg.index] = true
 		m.slotFailed[msg.index] = true
+		m.slotErr[msg.index] = msg.err
 		m.finished++
 		m.failed++
 		if m.completed == 0 && m.finished >= m.total {
@@ -341,12 +471,18 @@ func (m Model) handleGenerationMessage(msg messageReadyMsg) (tea.Model, tea.Cmd)
 	if msg.done {
 		m.slotDone[msg.index] = true
 		m.finished++
-		if msg.content == "" {
+		if msg.usage != nil {
+			m.usage.Add(*msg.usage)
+		}
+		content := msg.suggestion.Message()
+		if content == "" {
 			m.slotFailed[msg.index] = true
+			m.slotErr[msg.index] = llm.ErrEmptyResponse
 			m.failed++
 		} else {
-			m.partial[msg.index] = msg.content
-			m.messages = append(m.messages, msg.content)
+			m.partial[msg.index] = msg.suggestion.Header()
+			m.messages = append(m.messages, content)
+			m.suggestions = append(m.suggestions, msg.suggestion)
 			m.completed++
 			if m.phase == PhaseLoading {
 				m.phase = PhaseSelect
@@ -368,10 +504,58 @@ func (m Model) pendingCount() int {
 	return pending
 }
 
-// Run starts the TUI program.
-func Run(cfg *config.Config, diff, stat string) error {
-	m := NewModel(cfg, diff, stat)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err := p.Run()
+// Options adjusts the TUI for one run.
+type Options struct {
+	// Push preselects "commit & push" on the confirm screen.
+	Push bool
+	// Amend rewrites HEAD with the selected message; the diff must cover
+	// HEAD and the staged changes.
+	Amend bool
+	// SelectFiles starts with the file selection phase; the diff and stat
+	// passed to Run are then ignored and read after staging.
+	SelectFiles bool
+	// PushOptions are sent with -o in addition to the configured ones.
+	PushOptions []string
+}
+
+// resolveCredentials builds the provider once before the TUI takes over the
+// terminal, so an interactive api_key_cmd (pinentry, a password manager
+// sign-in) can prompt. Its output is cached for the NewProvider calls made
+// while the TUI runs.
+func resolveCredentials(cfg *config.Config) error {
+	_, err := llm.NewProvider(cfg)
 	return err
 }
+
+// Run starts the TUI program for repo. It reports whether a commit was
+// made; false with a nil error means the user quit without committing.
+func Run(cfg *config.Config, repo *git.Repo, diff, stat string, opts Options) (bool, error) {
+	if err := resolveCredentials(cfg); err != nil {
+		return false, err
+	}
+	m := NewModel(cfg, repo, diff, stat)
+	m.pushByDefault = opts.Push
+	if opts.Amend {
+		m.amend = true
+		m.previousMessage, _ = repo.HeadMessage()
+		m.headPushed = repo.HeadPushed()
+	}
+	m.pushTarget, _ = repo.CurrentPushTarget()
+	m.pushRemote = m.pushTarget.DefaultRemote(cfg.Push.Remote)
+	m.pushOptions = append(slices.Clone(cfg.Push.Options), opts.PushOptions...)
+	m.confirmCursor = m.defaultConfirmCursor()
+	if opts.SelectFiles {
+		files, err := loadFileEntries(repo)
+		if err != nil {
+			return false, err
+		}
+		m.files = files
+		m.phase = PhaseFiles
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return false, err
+	}
+	return final.(Model).committed, nil
+}
@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// diffFileEntry is one file in the diff viewer.
+type diffFileEntry struct {
+	path string
+	// line is the first line of the file in the rendered diff.
+	line    int
+	added   int
+	deleted int
+}
+
+// renderDiffView colors a multi-file unified diff and indexes its files.
+func renderDiffView(diff string) (string, []diffFileEntry) {
+	var files []diffFileEntry
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	out := make([]string, len(lines))
+	inHeader := false
+	for i, line := range lines {
+		line = expandTabs(line)
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			path := strings.TrimPrefix(line, "diff --git ")
+			if idx := strings.LastIndex(line, " b/"); idx >= 0 {
+				path = line[idx+3:]
+			}
+			files = append(files, diffFileEntry{path: path, line: i})
+			inHeader = true
+			out[i] = diffFileStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+			out[i] = hunkHeaderStyle.Render(line)
+		case inHeader:
+			out[i] = dimStyle.Render(line)
+		default:
+			if len(files) > 0 {
+				f := &files[len(files)-1]
+				switch {
+				case strings.HasPrefix(line, "+"):
+					f.added++
+				case strings.HasPrefix(line, "-"):
+					f.deleted++
+				}
+			}
+			out[i] = renderDiffLine(line)
+		}
+	}
+	return strings.Join(out, "\n"), files
+}
+
+// openDiff shows the diff viewer over the current phase.
+func (m Model) openDiff() Model {
+	content, files := renderDiffView(m.diff)
+	m.diffFiles = files
+	m.diffFile = 0
+	m.diffView = viewport.New(0, 0)
+	m.diffView.SetContent(content)
+	m.resizeDiffView()
+	m.showDiff = true
+	return m
+}
+
+// resizeDiffView fits the viewport next to the file list, if shown.
+func (m *Model) resizeDiffView() {
+	_, main := diffPaneWidths(m.contentWidth())
+	m.diffView.Width = main
+	m.diffView.Height = m.diffViewHeight()
+}
+
+// diffViewHeight is the number of diff lines shown.
+func (m Model) diffViewHeight() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-12, 5)
+}
+
+// diffFileAt returns the index of the file at line of the diff, or -1.
+func (m Model) diffFileAt(line int) int {
+	current := -1
+	for i, f := range m.diffFiles {
+		if f.line > line {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+func (m Model) updateDiff(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Diff), key.Matches(msg, keys.Escape), key.Matches(msg, keys.Quit):
+			m.showDiff = false
+			return m, nil
+		case key.Matches(msg, keys.NextFile):
+			if m.diffFile < len(m.diffFiles)-1 {
+				m.diffFile++
+				m.diffView.SetYOffset(m.diffFiles[m.diffFile].line)
+			}
+			return m, nil
+		case key.Matches(msg, keys.PrevFile):
+			if len(m.diffFiles) == 0 {
+				return m, nil
+			}
+			// Back to the start of the current file first.
+			if m.diffView.YOffset <= m.diffFiles[m.diffFile].line && m.diffFile > 0 {
+				m.diffFile--
+			}
+			m.diffView.SetYOffset(m.diffFiles[m.diffFile].line)
+			return m, nil
+		case key.Matches(msg, keys.Top):
+			m.diffView.GotoTop()
+			m.diffFile = 0
+			return m, nil
+		case key.Matches(msg, keys.Bottom):
+			m.diffView.GotoBottom()
+			m.diffFile = max(m.diffFileAt(m.diffView.YOffset), 0)
+			return m, nil
+		}
+	}
+
+	// Arrows, j/k and pgup/pgdn scroll the viewport.
+	offset := m.diffView.YOffset
+	var cmd tea.Cmd
+	m.diffView, cmd = m.diffView.Update(msg)
+	if m.diffView.YOffset != offset {
+		m.diffFile = max(m.diffFileAt(m.diffView.YOffset), 0)
+	}
+	return m, cmd
+}
+
+func (m Model) viewDiff() string {
+	var b strings.Builder
+	contentWidth := m.contentWidth()
+	side, _ := diffPaneWidths(contentWidth)
+
+	b.WriteString(titleStyle.Render("🔥 fire-commit"))
+	b.WriteString("\n\n")
+
+	current := m.diffFile
+	if len(m.diffFiles) == 0 {
+		b.WriteString(dimStyle.Render("No changes to show."))
+		b.WriteString("\n\n")
+	} else {
+		label := fmt.Sprintf("File %d/%d  ", current+1, len(m.diffFiles))
+		path := m.diffFiles[current].path
+		b.WriteString(dimStyle.Render(label))
+		b.WriteString(selectedStyle.Render(truncateWidth(path, contentWidth-len(label)-6)))
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  %3.f%%", m.diffView.ScrollPercent()*100)))
+		b.WriteString("\n\n")
+	}
+
+	if side > 0 {
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, m.viewDiffFiles(side, current), " ", m.diffView.View()))
+	} else {
+		b.WriteString(m.diffView.View())
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  ↑/↓ scroll • pgup/pgdn page • n/N next/prev file • ←/→ pan • g/G top/bottom • d/esc close"))
+
+	return m.renderBox(b.String())
+}
+
+// viewDiffFiles renders the file list panel, highlighting current.
+func (m Model) viewDiffFiles(width, current int) string {
+	height := m.diffView.Height
+	start, end := visibleRange(len(m.diffFiles), current, height)
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		f := m.diffFiles[i]
+		stat := fmt.Sprintf("+%d -%d", f.added, f.deleted)
+		// Keep room for the marker, the stat and the panel border.
+		name := truncateWidth(f.path, width-len(stat)-5)
+		pad := max(width-len([]rune(name))-len(stat)-5, 1)
+		if i == current {
+			b.WriteString(cursorStyle.Render("> ") + selectedStyle.Render(name))
+		} else {
+			b.WriteString("  " + normalStyle.Render(name))
+		}
+		b.WriteString(strings.Repeat(" ", pad))
+		b.WriteString(dimStyle.Render(stat))
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+	return diffPanelStyle.Width(width - 1).Height(height).Render(b.String())
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+const diffViewTestDiff = `diff --git a/api.go b/api.go
+index 1111111..2222222 100644
+--- a/api.go
++++ b/api.go
+@@ -1,2 +1,3 @@
+ package api
++func A() {}
+-func B() {}
++func C() {}
+diff --git a/docs/readme.md b/docs/readme.md
+--- a/docs/readme.md
++++ b/docs/readme.md
+@@ -1 +1 @@
+-old
++new
+`
+
+func newDiffViewTestModel(width int) Model {
+	m := NewModel(config.DefaultConfig(), git.Open(""), diffViewTestDiff, "")
+	next, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 30})
+	return next.(Model)
+}
+
+func TestRenderDiffViewIndexesFiles(t *testing.T) {
+	t.Parallel()
+
+	_, files := renderDiffView(diffViewTestDiff)
+	if len(files) != 2 {
+		t.Fatalf("files got %d want 2", len(files))
+	}
+	// Header "---"/"+++" lines are not counted as changes.
+	if f := files[0]; f.path != "api.go" || f.line != 0 || f.added != 2 || f.deleted != 1 {
+		t.Fatalf("first file got %+v", f)
+	}
+	if f := files[1]; f.path != "docs/readme.md" || f.line != 9 || f.added != 1 || f.deleted != 1 {
+		t.Fatalf("second file got %+v", f)
+	}
+}
+
+func TestDiffViewTogglesAndNavigatesFiles(t *testing.T) {
+	t.Parallel()
+
+	m := newDiffViewTestModel(80)
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = next.(Model)
+	if !m.showDiff {
+		t.Fatalf("d should open the diff viewer")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = next.(Model)
+	if m.diffFile != 1 {
+		t.Fatalf("n should move to the second file, at file %d", m.diffFile)
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	m = next.(Model)
+	if m.diffFile != 0 || m.diffView.YOffset != 0 {
+		t.Fatalf("N should move back to the first file, at file %d offset %d", m.diffFile, m.diffView.YOffset)
+	}
+
+	// q closes the viewer instead of quitting.
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	m = next.(Model)
+	if m.showDiff || m.phase != PhaseLoading {
+		t.Fatalf("q should close the viewer, showDiff=%v phase=%v", m.showDiff, m.phase)
+	}
+}
+
+func TestDiffViewShowsFileListOnWideTerminals(t *testing.T) {
+	t.Parallel()
+
+	narrow := newDiffViewTestModel(80).openDiff()
+	if side, _ := diffPaneWidths(narrow.contentWidth()); side != 0 {
+		t.Fatalf("narrow terminal should not get a file list, side %d", side)
+	}
+
+	wide := newDiffViewTestModel(160).openDiff()
+	side, main := diffPaneWidths(wide.contentWidth())
+	if side == 0 || wide.diffView.Width != main {
+		t.Fatalf("wide terminal got side=%d main=%d viewport=%d", side, main, wide.diffView.Width)
+	}
+	view := wide.viewDiff()
+	if !strings.Contains(view, "docs/readme.md") {
+		t.Fatalf("file list missing from view:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if lipgloss.Width(line) > 160 {
+			t.Fatalf("line exceeds terminal width: %q", line)
+		}
+	}
+}
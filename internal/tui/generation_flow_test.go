@@ -1,15 +1,19 @@
 package tui
 
 import (
+	"strings"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+	"github.com/lieyanc/fire-commit/internal/llm"
 )
 
 func newGenerationTestModel() Model {
 	cfg := config.DefaultConfig()
 	cfg.Generation.NumSuggestions = 3
-	return NewModel(cfg, "diff", "stat")
+	return NewModel(cfg, git.Open(""), "diff", "stat")
 }
 
 func TestUpdateSelectsEarlyWhenFirstMessageReady(t *testing.T) {
@@ -20,7 +24,7 @@ func TestUpdateSelectsEarlyWhenFirstMessageReady(t *testing.T) {
 	next, _ := m.Update(messageReadyMsg{
 		generationID: m.generationID,
 		index:        0,
-		content:      "feat(api): add endpoint",
+		suggestion:   llm.ParseHeader("feat(api): add endpoint"),
 		done:         true,
 	})
 	got := next.(Model)
@@ -67,7 +71,7 @@ func TestUpdateAppendsStreamDeltasPerSlot(t *testing.T) {
 	next, _ = got.Update(messageReadyMsg{
 		generationID: got.generationID,
 		index:        1,
-		content:      "feat(api): add endpoint",
+		suggestion:   llm.ParseHeader("feat(api): add endpoint"),
 		done:         true,
 	})
 	got = next.(Model)
@@ -79,6 +83,27 @@ func TestUpdateAppendsStreamDeltasPerSlot(t *testing.T) {
 	}
 }
 
+func TestLoadingPreviewParsesStructuredDeltas(t *testing.T) {
+	t.Parallel()
+
+	m := newGenerationTestModel()
+	m.cfg.Generation.StructuredOutput = true
+
+	next, _ := m.Update(messageReadyMsg{generationID: m.generationID, index: 0, delta: `{"type":"feat","scope":"api",`})
+	m = next.(Model)
+	view := m.viewLoading()
+	if strings.Contains(view, `"type"`) || !strings.Contains(view, "(writing suggestion...)") {
+		t.Fatalf("loading view should hide raw JSON before the description:\n%s", view)
+	}
+
+	next, _ = m.Update(messageReadyMsg{generationID: m.generationID, index: 0, delta: `"breaking":false,"description":"add endpo`})
+	m = next.(Model)
+	view = m.viewLoading()
+	if strings.Contains(view, `"type"`) || !strings.Contains(view, "feat(api): add endpo") {
+		t.Fatalf("loading view should show the partial header:\n%s", view)
+	}
+}
+
 func TestUpdateIgnoresStaleGenerationEvents(t *testing.T) {
 	t.Parallel()
 
@@ -88,7 +113,7 @@ func TestUpdateIgnoresStaleGenerationEvents(t *testing.T) {
 	next, _ := m.Update(messageReadyMsg{
 		generationID: 1,
 		index:        0,
-		content:      "feat: stale",
+		suggestion:   llm.ParseHeader("feat: stale"),
 		done:         true,
 	})
 	got := next.(Model)
@@ -100,3 +125,98 @@ func TestUpdateIgnoresStaleGenerationEvents(t *testing.T) {
 		t.Fatalf("phase got %v want %v", got.phase, PhaseLoading)
 	}
 }
+
+func TestRefineAddsFeedbackTurnsAndRegenerates(t *testing.T) {
+	t.Parallel()
+
+	m := newGenerationTestModel()
+	next, _ := m.Update(messageReadyMsg{
+		generationID: m.generationID,
+		index:        0,
+		suggestion:   llm.ParseHeader("feat(api): add endpoint"),
+		done:         true,
+	})
+	got := next.(Model)
+	prevID := got.generationID
+
+	next, _ = got.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	got = next.(Model)
+	if !got.refining {
+		t.Fatalf("expected refine input to open")
+	}
+	next, _ = got.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("scope is http")})
+	got = next.(Model)
+	next, _ = got.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	got = next.(Model)
+
+	if got.refining || got.phase != PhaseLoading || got.generationID != prevID+1 {
+		t.Fatalf("got refining=%v phase=%v generation=%d", got.refining, got.phase, got.generationID)
+	}
+	want := llm.RefinementMessages("feat(api): add endpoint", "scope is http")
+	if len(got.conversation) != 2 || got.conversation[0] != want[0] || got.conversation[1] != want[1] {
+		t.Fatalf("conversation = %+v", got.conversation)
+	}
+}
+
+func TestRefineEscapeKeepsSuggestions(t *testing.T) {
+	t.Parallel()
+
+	m := newGenerationTestModel()
+	next, _ := m.Update(messageReadyMsg{
+		generationID: m.generationID,
+		index:        0,
+		suggestion:   llm.ParseHeader("fix: handle nil config"),
+		done:         true,
+	})
+	got := next.(Model)
+
+	next, _ = got.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyEscape})
+	got = next.(Model)
+
+	if got.refining || got.phase != PhaseSelect || len(got.messages) != 1 || len(got.conversation) != 0 {
+		t.Fatalf("got refining=%v phase=%v messages=%d conversation=%d", got.refining, got.phase, len(got.messages), len(got.conversation))
+	}
+}
+
+func TestConfirmWarnsWhenAmendingPushedHead(t *testing.T) {
+	t.Parallel()
+
+	m := newGenerationTestModel()
+	m.amend = true
+	m.previousMessage = "wip\n\nbody"
+	m.messages = []string{"feat(api): add endpoint"}
+	m.phase = PhaseConfirm
+
+	view := m.viewConfirm()
+	if !strings.Contains(view, "Replaces HEAD: wip") || !strings.Contains(view, "Amend only") {
+		t.Fatalf("amend confirm view missing amend details:\n%s", view)
+	}
+	if strings.Contains(view, "already pushed") {
+		t.Fatalf("warning shown for unpushed HEAD")
+	}
+
+	m.headPushed = true
+	if view := m.viewConfirm(); !strings.Contains(view, "already pushed") {
+		t.Fatalf("missing pushed warning:\n%s", view)
+	}
+}
+
+func TestSelectMarksOfflineSuggestions(t *testing.T) {
+	t.Parallel()
+
+	m := newGenerationTestModel()
+	s := llm.ParseHeader("docs: update README.md")
+	s.Offline = true
+	next, _ := m.Update(messageReadyMsg{generationID: m.generationID, index: 0, suggestion: s, done: true})
+	got := next.(Model)
+
+	if got.offlineCount() != 1 {
+		t.Fatalf("offlineCount() = %d, want 1", got.offlineCount())
+	}
+	view := got.viewSelect()
+	if !strings.Contains(view, "offline heuristic") {
+		t.Fatalf("select view does not mark the offline suggestion:\n%s", view)
+	}
+}
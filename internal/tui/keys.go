@@ -3,20 +3,44 @@ package tui
 import "github.com/charmbracelet/bubbles/key"
 
 type keyMap struct {
-	Up      key.Binding
-	Down    key.Binding
-	Enter   key.Binding
-	Edit    key.Binding
-	Regen   key.Binding
-	Push    key.Binding
-	Save    key.Binding
-	Tab     key.Binding
-	Quit    key.Binding
-	Escape  key.Binding
-	CtrlC   key.Binding
-	Version key.Binding
-	TagInc1 key.Binding
-	TagInc2 key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Enter     key.Binding
+	Edit      key.Binding
+	Regen     key.Binding
+	Refine    key.Binding
+	Push      key.Binding
+	Save      key.Binding
+	Tab       key.Binding
+	Quit      key.Binding
+	Escape    key.Binding
+	CtrlC     key.Binding
+	Version   key.Binding
+	TagInc1   key.Binding
+	TagInc2   key.Binding
+	Accept    key.Binding
+	Skip      key.Binding
+	Toggle    key.Binding
+	ToggleAll key.Binding
+	Open      key.Binding
+	PageUp    key.Binding
+	PageDown  key.Binding
+	Stage     key.Binding
+	MovePrev  key.Binding
+	MoveNext  key.Binding
+	NewGroup  key.Binding
+	GroupUp   key.Binding
+	GroupDown key.Binding
+	Diff      key.Binding
+	NextFile  key.Binding
+	PrevFile  key.Binding
+	Top       key.Binding
+	Bottom    key.Binding
+	Sign      key.Binding
+	Signoff   key.Binding
+	NoVerify  key.Binding
+	Trailers  key.Binding
+	Remote    key.Binding
 }
 
 var keys = keyMap{
@@ -40,6 +64,10 @@ var keys = keyMap{
 		key.WithKeys("r"),
 		key.WithHelp("r", "regenerate"),
 	),
+	Refine: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "refine"),
+	),
 	Push: key.NewBinding(
 		key.WithKeys("p"),
 		key.WithHelp("p", "push"),
@@ -75,4 +103,96 @@ var keys = keyMap{
 		key.WithKeys("alt+2"),
 		key.WithHelp("alt+2", "+0.01"),
 	),
+	Accept: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "accept"),
+	),
+	Skip: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "skip"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle"),
+	),
+	ToggleAll: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "all/none"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "hunks"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup", "ctrl+u"),
+		key.WithHelp("pgup", "scroll up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown", "ctrl+d"),
+		key.WithHelp("pgdn", "scroll down"),
+	),
+	Stage: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "stage files"),
+	),
+	MovePrev: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "previous commit"),
+	),
+	MoveNext: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "next commit"),
+	),
+	NewGroup: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new commit"),
+	),
+	GroupUp: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "move commit up"),
+	),
+	GroupDown: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "move commit down"),
+	),
+	Diff: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "diff"),
+	),
+	NextFile: key.NewBinding(
+		key.WithKeys("n", "]", "tab"),
+		key.WithHelp("n", "next file"),
+	),
+	PrevFile: key.NewBinding(
+		key.WithKeys("N", "[", "shift+tab"),
+		key.WithHelp("N", "previous file"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("g", "home"),
+		key.WithHelp("g", "top"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("G", "end"),
+		key.WithHelp("G", "bottom"),
+	),
+	Sign: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "sign"),
+	),
+	Signoff: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "signoff"),
+	),
+	NoVerify: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "no-verify"),
+	),
+	Trailers: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "trailers"),
+	),
+	Remote: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "remote"),
+	),
 }
@@ -42,23 +42,58 @@ func (m *Model) resizeInputs() {
 		tagWidth = 12
 	}
 	m.tagInput.Width = tagWidth
+
+	refineWidth := contentWidth - len("Feedback: ") - 2
+	if refineWidth < minInputWidth {
+		refineWidth = minInputWidth
+	}
+	m.refineInput.Width = refineWidth
+
+	m.resizeDiffView()
 }
 
 func (m Model) contentWidth() int {
-	if m.width <= 0 {
+	return contentWidthFor(m.width)
+}
+
+// contentWidthFor returns the usable text width inside the frame for a
+// terminal of the given width (0 = unknown).
+func contentWidthFor(termWidth int) int {
+	if termWidth <= 0 {
 		return fallbackContentWidth
 	}
-	width := m.width - boxStyle.GetHorizontalFrameSize() - 2
+	width := termWidth - boxStyle.GetHorizontalFrameSize() - 2
 	if width < minContentWidth {
 		return minContentWidth
 	}
 	return width
 }
 
+// diffSidePanelMinWidth is the content width from which the diff viewer
+// shows the file list next to the diff.
+const diffSidePanelMinWidth = 100
+
+// diffPaneWidths splits contentWidth between the diff viewer's file list and
+// the diff itself. side is 0 when the terminal is too narrow for the list.
+func diffPaneWidths(contentWidth int) (side, main int) {
+	if contentWidth < diffSidePanelMinWidth {
+		return 0, contentWidth
+	}
+	side = min(max(contentWidth/4, 24), 40)
+	// One column for the gap between the panes.
+	return side, contentWidth - side - 1
+}
+
 func (m Model) renderBox(content string) string {
+	return renderBoxWidth(content, m.width)
+}
+
+// renderBoxWidth draws content in the app frame, clipped to a terminal of the
+// given width (0 = unknown).
+func renderBoxWidth(content string, width int) string {
 	style := boxStyle
-	if m.width > 0 {
-		maxWidth := m.width - 2
+	if width > 0 {
+		maxWidth := width - 2
 		if maxWidth > 0 {
 			style = style.MaxWidth(maxWidth)
 		}
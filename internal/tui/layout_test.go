@@ -8,6 +8,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
 )
 
 func TestWrapTextHonorsWidth(t *testing.T) {
@@ -43,7 +44,7 @@ func TestResetForRegenerationClearsTransientState(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.Generation.NumSuggestions = 4
 
-	m := NewModel(cfg, "diff", "stat")
+	m := NewModel(cfg, git.Open(""), "diff", "stat")
 	m.cursor = 3
 	m.completed = 2
 	m.finished = 3
@@ -96,7 +97,7 @@ func TestUpdateConfirmPushToggle(t *testing.T) {
 	t.Parallel()
 
 	cfg := config.DefaultConfig()
-	m := NewModel(cfg, "diff", "stat")
+	m := NewModel(cfg, git.Open(""), "diff", "stat")
 	m.phase = PhaseConfirm
 	m.confirmCursor = confirmCommitOnly
 
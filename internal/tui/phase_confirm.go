@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -18,10 +19,21 @@ const (
 	confirmOptionCount = 3
 )
 
+// defaultConfirmCursor is the option highlighted when the confirm screen opens.
+func (m Model) defaultConfirmCursor() int {
+	if m.pushByDefault {
+		return confirmCommitAndPush
+	}
+	return confirmCommitOnly
+}
+
 func (m Model) updateConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.editingTag {
 		return m.updateTagInput(msg)
 	}
+	if m.pickingTrailers {
+		return m.updateTrailerPicker(msg)
+	}
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -51,6 +63,20 @@ func (m Model) updateConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.tagInput.Focus()
 				return m, m.tagInput.Cursor.BlinkCmd()
 			}
+		case key.Matches(msg, keys.Sign):
+			m.commitOpts.Sign = !m.commitOpts.Sign
+		case key.Matches(msg, keys.Signoff):
+			m.commitOpts.Signoff = !m.commitOpts.Signoff
+		case key.Matches(msg, keys.NoVerify):
+			m.commitOpts.NoVerify = !m.commitOpts.NoVerify
+		case key.Matches(msg, keys.Trailers):
+			if len(m.trailers) > 0 {
+				m.pickingTrailers = true
+			}
+		case key.Matches(msg, keys.Remote):
+			m.nextPushRemote()
+		case key.Matches(msg, keys.Diff):
+			return m.openDiff(), nil
 		case key.Matches(msg, keys.Enter):
 			switch m.confirmCursor {
 			case confirmCommitAndPush:
@@ -112,7 +138,7 @@ func (m Model) updateTagInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) refreshTagHints() {
-	hints := buildTagHints(git.LatestTag())
+	hints := buildTagHints(m.repo.LatestTag())
 	m.tagHintBase = hints.base
 	m.tagHintMinor = hints.minor
 	m.tagHintPatch = hints.patch
@@ -140,6 +166,17 @@ func (m Model) viewConfirm() string {
 	b.WriteString(renderWrappedLine("  ", "  ", m.messages[m.cursor], highlightStyle, contentWidth))
 	b.WriteString("\n\n")
 
+	if m.amend {
+		subject, _, _ := strings.Cut(m.previousMessage, "\n")
+		b.WriteString(dimStyle.Render(wrapText("Replaces HEAD: "+subject, contentWidth)))
+		b.WriteString("\n")
+		if m.headPushed {
+			b.WriteString(errorStyle.Render(wrapText("⚠ HEAD is already pushed to its upstream; amending rewrites published history.", contentWidth)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
 	// Version tag line
 	if m.editingTag {
 		b.WriteString("Version tag: ")
@@ -158,7 +195,31 @@ func (m Model) viewConfirm() string {
 		b.WriteString("\n\n")
 	}
 
+	b.WriteString("Options:  ")
+	b.WriteString(renderToggle("sign", m.commitOpts.Sign))
+	b.WriteString("  " + renderToggle("signoff", m.commitOpts.Signoff))
+	b.WriteString("  " + renderToggle("no-verify", m.commitOpts.NoVerify))
+	b.WriteString("\n")
+	for _, t := range m.selectedTrailers() {
+		b.WriteString(renderWrappedLine("  ", "  ", t, dimStyle, contentWidth))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.pickingTrailers {
+		b.WriteString(m.viewTrailerPicker())
+		return m.renderBox(b.String())
+	}
+
+	if m.confirmCursor == confirmCommitAndPush {
+		b.WriteString(m.viewPushPlan(contentWidth))
+		b.WriteString("\n\n")
+	}
+
 	options := []string{"Commit & Push", "Commit only", "Cancel"}
+	if m.amend {
+		options = []string{"Amend & Push", "Amend only", "Cancel"}
+	}
 	for i, opt := range options {
 		if i == m.confirmCursor {
 			b.WriteString(renderWrappedLine("  > ", cursorStyle.Render("  > "), opt, selectedStyle, contentWidth))
@@ -171,39 +232,63 @@ func (m Model) viewConfirm() string {
 	if m.editingTag {
 		b.WriteString(helpStyle.Render("\n  enter set tag • alt+1/+0.1 bump minor • alt+2/+0.01 bump patch • esc back • q quit"))
 	} else {
-		b.WriteString(helpStyle.Render("\n  ↑/↓/tab select • enter confirm • p toggle push • v version • esc back • q quit"))
+		help := []string{"↑/↓/tab select", "enter confirm", "p toggle push", "v version", "s sign", "o signoff", "n no-verify"}
+		if len(m.trailers) > 0 {
+			help = append(help, "t trailers")
+		}
+		if m.confirmCursor == confirmCommitAndPush && m.pushTarget.Upstream == "" && len(m.pushTarget.Remotes) > 1 {
+			help = append(help, "u remote")
+		}
+		help = append(help, "d diff", "esc back", "q quit")
+		b.WriteString(helpStyle.Render("\n  " + strings.Join(help, " • ")))
 	}
 
 	return m.renderBox(b.String())
 }
 
+// renderToggle shows an on/off option of the confirm screen.
+func renderToggle(label string, on bool) string {
+	if on {
+		return selectedStyle.Render("[x] " + label)
+	}
+	return dimStyle.Render("[ ] " + label)
+}
+
 func (m Model) doCommit() tea.Cmd {
 	msg := m.messages[m.cursor]
+	opts := m.commitOpts
+	opts.Amend = m.amend
+	opts.Trailers = m.selectedTrailers()
+	repo := m.repo
 	return func() tea.Msg {
-		err := git.Commit(msg)
+		err := repo.CommitWith(msg, opts)
 		return commitDoneMsg{err: err}
 	}
 }
 
-func (m Model) doTag() tea.Cmd {
-	tag := m.versionTag
-	return func() tea.Msg {
-		err := git.Tag(tag)
-		return tagDoneMsg{err: err}
+// commitVerb describes the completed commit in the result views.
+func (m Model) commitVerb() string {
+	if m.amend {
+		return "Amended"
 	}
+	return "Committed"
 }
 
-func (m Model) doGitPush() tea.Cmd {
+func (m Model) doTag() tea.Cmd {
+	tag := m.versionTag
+	repo := m.repo
 	return func() tea.Msg {
-		err := git.Push()
-		return pushDoneMsg{err: err}
+		err := repo.Tag(tag)
+		return tagDoneMsg{err: err}
 	}
 }
 
 func (m Model) doPushTag() tea.Cmd {
 	tag := m.versionTag
+	remote := m.tagRemote()
+	repo := m.repo
 	return func() tea.Msg {
-		err := git.PushTag(tag)
+		err := repo.PushTagTo(remote, tag)
 		return tagPushDoneMsg{err: err}
 	}
 }
@@ -222,33 +307,44 @@ func (m Model) updateCommitting(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.committed = true
-		if m.versionTag != "" {
-			return m, tea.Batch(m.spinner.Tick, m.doTag())
-		}
-		if m.wantPush {
-			return m, tea.Batch(m.spinner.Tick, m.doGitPush())
+		return m.continueAfterCommit()
+
+	case fetchDoneMsg, syncDoneMsg:
+		return m.updateSync(msg)
+
+	case tea.KeyMsg:
+		if m.syncPrompt {
+			return m.updateSync(msg)
 		}
-		m.phase = PhaseDone
 		return m, nil
 
 	case tagDoneMsg:
 		if msg.err != nil {
 			m.tagErr = msg.err
 			// Tag failed, but still proceed to push commit if wanted
-			if m.wantPush {
+			if m.pushPending() {
 				return m, tea.Batch(m.spinner.Tick, m.doGitPush())
 			}
 			m.phase = PhaseDone
 			return m, nil
 		}
 		m.tagged = true
-		if m.wantPush {
+		if m.pushPending() {
 			return m, tea.Batch(m.spinner.Tick, m.doGitPush())
 		}
 		m.phase = PhaseDone
 		return m, nil
 
+	case pushStartedMsg:
+		m.pushCh = msg.ch
+		return m, waitForPush(m.pushCh)
+
+	case pushProgressMsg:
+		m.pushProgress = msg.line
+		return m, waitForPush(m.pushCh)
+
 	case pushDoneMsg:
+		m.pushCh = nil
 		m.pushErr = msg.err
 		m.pushed = msg.err == nil
 		// If tag was created successfully, also push the tag
@@ -276,13 +372,20 @@ func (m Model) viewCommitting() string {
 	b.WriteString("\n\n")
 
 	if m.committed {
-		b.WriteString(successStyle.Render("✓ Committed"))
+		b.WriteString(successStyle.Render("✓ " + m.commitVerb()))
 		b.WriteString("\n")
+	} else if m.amend {
+		b.WriteString(m.spinner.View() + " Amending...")
+		return m.renderBox(b.String())
 	} else {
 		b.WriteString(m.spinner.View() + " Committing...")
 		return m.renderBox(b.String())
 	}
 
+	if m.wantPush && m.viewSync(&b, m.contentWidth()) {
+		return m.renderBox(b.String())
+	}
+
 	if m.versionTag != "" {
 		if m.tagged {
 			b.WriteString(successStyle.Render("✓ Tagged: " + m.versionTag))
@@ -296,7 +399,7 @@ func (m Model) viewCommitting() string {
 		}
 	}
 
-	if m.wantPush {
+	if m.pushPending() {
 		if m.pushed {
 			b.WriteString(successStyle.Render("✓ Pushed"))
 			b.WriteString("\n")
@@ -304,7 +407,8 @@ func (m Model) viewCommitting() string {
 			b.WriteString(errorStyle.Render(fmt.Sprintf("✗ Push failed: %s", m.pushErr)))
 			b.WriteString("\n")
 		} else {
-			b.WriteString(m.spinner.View() + " Pushing...")
+			b.WriteString(m.spinner.View() + " Pushing to " + m.pushDestination() + "...")
+			b.WriteString(m.pushProgressLine(m.contentWidth()))
 			return m.renderBox(b.String())
 		}
 
@@ -341,6 +445,18 @@ func (m Model) viewDone() string {
 	b.WriteString(titleStyle.Render("🔥 fire-commit"))
 	b.WriteString("\n\n")
 
+	var commitErr *git.CommitError
+	if errors.As(m.commitErr, &commitErr) {
+		b.WriteString(errorStyle.Render("✗ " + m.commitVerb() + " failed"))
+		b.WriteString("\n\n")
+		b.WriteString(renderCommitOutput(commitErr.Output, contentWidth, m.commitOutputHeight()))
+		if !m.commitOpts.NoVerify {
+			b.WriteString("\n\n")
+			b.WriteString(dimStyle.Render(wrapText("If a hook rejected the commit, fix the problem or enable no-verify on the confirm screen.", contentWidth)))
+		}
+		b.WriteString(helpStyle.Render("\n\n  Press any key to exit"))
+		return m.renderBox(b.String())
+	}
 	if m.commitErr != nil {
 		b.WriteString(errorStyle.Render(wrapText(fmt.Sprintf("✗ Error: %s", m.commitErr), contentWidth)))
 		b.WriteString(helpStyle.Render("\n\n  Press any key to exit"))
@@ -348,12 +464,14 @@ func (m Model) viewDone() string {
 	}
 
 	if m.committed {
-		b.WriteString(successStyle.Render("✓ Committed:"))
+		b.WriteString(successStyle.Render("✓ " + m.commitVerb() + ":"))
 		b.WriteString("\n")
 		b.WriteString(renderWrappedLine("  ", "  ", m.messages[m.cursor], highlightStyle, contentWidth))
 		b.WriteString("\n")
 	}
 
+	m.viewSyncResult(&b, contentWidth)
+
 	if m.versionTag != "" {
 		if m.tagged {
 			b.WriteString(successStyle.Render("✓ Tagged: " + m.versionTag))
@@ -363,12 +481,11 @@ func (m Model) viewDone() string {
 		b.WriteString("\n")
 	}
 
-	if m.wantPush {
+	if m.pushPending() {
 		if m.pushErr != nil {
 			b.WriteString(errorStyle.Render(wrapText(fmt.Sprintf("✗ Push failed: %s", m.pushErr), contentWidth)))
 		} else if m.pushed {
-			branch, _ := git.CurrentBranch()
-			b.WriteString(successStyle.Render(fmt.Sprintf("✓ Pushed to origin/%s", branch)))
+			b.WriteString(successStyle.Render("✓ Pushed to " + m.pushDestination()))
 		}
 		b.WriteString("\n")
 
@@ -386,3 +503,34 @@ func (m Model) viewDone() string {
 
 	return m.renderBox(b.String())
 }
+
+// commitOutputHeight is the number of lines of git and hook output shown
+// when a commit fails.
+func (m Model) commitOutputHeight() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-14, 5)
+}
+
+// renderCommitOutput shows the last height lines of what git and its hooks
+// printed.
+func renderCommitOutput(output string, width, height int) string {
+	if output == "" {
+		return dimStyle.Render("(git printed nothing)")
+	}
+	lines := strings.Split(wrapText(expandTabs(output), width-2), "\n")
+	var b strings.Builder
+	if len(lines) > height {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  … %d earlier lines", len(lines)-height)))
+		b.WriteString("\n")
+		lines = lines[len(lines)-height:]
+	}
+	for i, l := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("  " + normalStyle.Render(l))
+	}
+	return b.String()
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/llm"
 )
 
 func (m Model) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -23,10 +24,15 @@ func (m Model) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 			value := strings.TrimSpace(m.editArea.Value())
 			if value != "" {
 				m.messages[m.cursor] = value
+				if m.cursor < len(m.suggestions) {
+					edited := llm.ParseHeader(firstLine(value))
+					edited.Body = strings.TrimSpace(strings.TrimPrefix(value, firstLine(value)))
+					m.suggestions[m.cursor] = edited
+				}
 			}
 			m.editing = false
 			m.editArea.Blur()
-			m.confirmCursor = confirmCommitOnly
+			m.confirmCursor = m.defaultConfirmCursor()
 			m.phase = PhaseConfirm
 			return m, nil
 		}
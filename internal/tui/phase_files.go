@@ -0,0 +1,333 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+// fileCheck is the checkbox state of one file in the file selection phase.
+type fileCheck int
+
+const (
+	fileUnchecked fileCheck = iota
+	fileChecked
+	// filePartial keeps a partially staged file as it is in the index.
+	filePartial
+)
+
+// fileEntry is one changed file offered for staging.
+type fileEntry struct {
+	status git.FileStatus
+	stat   git.FileStat
+	check  fileCheck
+}
+
+// paths returns the paths to pass to git for the entry, including the
+// source of a rename so both sides are staged or unstaged together.
+func (f fileEntry) paths() []string {
+	if f.status.OrigPath != "" {
+		return []string{f.status.Path, f.status.OrigPath}
+	}
+	return []string{f.status.Path}
+}
+
+// label describes the kind of change.
+func (f fileEntry) label() string {
+	switch {
+	case f.status.Untracked():
+		return "new"
+	case f.status.Deleted():
+		return "deleted"
+	case f.status.Index == 'R':
+		return "renamed"
+	case f.status.Index == 'A':
+		return "added"
+	default:
+		return "modified"
+	}
+}
+
+// filesStagedMsg reports the staged diff after applying the selection.
+type filesStagedMsg struct {
+	diff string
+	stat string
+	err  error
+}
+
+// loadFileEntries lists the changed files. Staged files start checked (or
+// partial when they also have unstaged changes); when nothing is staged yet,
+// changes to tracked files are preselected and untracked files are not.
+func loadFileEntries(repo *git.Repo) ([]fileEntry, error) {
+	statuses, err := repo.StatusFiles()
+	if err != nil {
+		return nil, err
+	}
+	stats := repo.FileStats(statuses)
+
+	anyStaged := false
+	for _, s := range statuses {
+		anyStaged = anyStaged || s.Staged()
+	}
+
+	entries := make([]fileEntry, len(statuses))
+	for i, s := range statuses {
+		e := fileEntry{status: s, stat: stats[s.Path]}
+		switch {
+		case s.Staged() && s.Unstaged():
+			e.check = filePartial
+		case s.Staged():
+			e.check = fileChecked
+		case !anyStaged && !s.Untracked():
+			e.check = fileChecked
+		}
+		entries[i] = e
+	}
+	return entries, nil
+}
+
+func (m Model) updateFiles(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case filesStagedMsg:
+		m.staging = false
+		if msg.err != nil {
+			m.filesErr = msg.err
+			return m, nil
+		}
+		if msg.diff == "" {
+			m.filesErr = fmt.Errorf("nothing staged — select at least one file")
+			return m, nil
+		}
+		m.diff = msg.diff
+		m.stat = msg.stat
+		m.filesErr = nil
+		m.resetForRegeneration()
+		m.phase = PhaseLoading
+		return m, tea.Batch(m.spinner.Tick, m.startGeneration())
+
+	case hunksLoadedMsg:
+		m.staging = false
+		if msg.err != nil {
+			m.filesErr = msg.err
+			return m, nil
+		}
+		m.hunkFile = msg.file
+		m.hunkDiff = msg.diff
+		m.hunkOn = msg.on
+		m.hunkCursor = 0
+		m.hunkScroll = 0
+		m.phase = PhaseHunks
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.staging {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.fileCursor > 0 {
+				m.fileCursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.fileCursor < len(m.files)-1 {
+				m.fileCursor++
+			}
+		case key.Matches(msg, keys.Toggle):
+			if len(m.files) > 0 {
+				f := &m.files[m.fileCursor]
+				if f.check == fileChecked {
+					f.check = fileUnchecked
+				} else {
+					f.check = fileChecked
+				}
+			}
+		case key.Matches(msg, keys.ToggleAll):
+			check := fileChecked
+			if m.allFilesChecked() {
+				check = fileUnchecked
+			}
+			for i := range m.files {
+				m.files[i].check = check
+			}
+		case key.Matches(msg, keys.Open):
+			if len(m.files) == 0 {
+				return m, nil
+			}
+			if !m.files[m.fileCursor].canBrowseHunks() {
+				m.filesErr = fmt.Errorf("%s can only be staged as a whole", m.files[m.fileCursor].status.Path)
+				return m, nil
+			}
+			m.staging = true
+			m.filesErr = nil
+			return m, m.loadHunks(m.fileCursor)
+		case key.Matches(msg, keys.Enter):
+			m.staging = true
+			m.filesErr = nil
+			return m, m.stageSelection()
+		case key.Matches(msg, keys.Escape):
+			// Back to the suggestions when coming from the select screen.
+			if len(m.messages) > 0 {
+				m.phase = PhaseSelect
+			}
+		case key.Matches(msg, keys.Quit):
+			m.cancel()
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m Model) allFilesChecked() bool {
+	for _, f := range m.files {
+		if f.check != fileChecked {
+			return false
+		}
+	}
+	return true
+}
+
+// stageSelection applies the checkboxes to the index and reads the new
+// staged diff.
+func (m Model) stageSelection() tea.Cmd {
+	var stage, unstage []string
+	for _, f := range m.files {
+		switch f.check {
+		case fileChecked:
+			stage = append(stage, f.paths()...)
+		case fileUnchecked:
+			// The hunk browser may have staged part of the file since the
+			// list was loaded, so the loaded status is not enough.
+			unstage = append(unstage, f.paths()...)
+		}
+	}
+	repo := m.repo
+	maxLines := m.cfg.Generation.MaxDiffLines
+	return func() tea.Msg {
+		if err := repo.UnstagePaths(unstage); err != nil {
+			return filesStagedMsg{err: err}
+		}
+		if err := repo.StagePaths(stage); err != nil {
+			return filesStagedMsg{err: err}
+		}
+		diff, err := repo.StagedDiff(maxLines)
+		if err != nil {
+			return filesStagedMsg{err: err}
+		}
+		stat, _ := repo.DiffStat()
+		return filesStagedMsg{diff: diff, stat: stat}
+	}
+}
+
+func (m Model) viewFiles() string {
+	var b strings.Builder
+	contentWidth := m.contentWidth()
+
+	b.WriteString(titleStyle.Render("🔥 fire-commit"))
+	b.WriteString("\n\n")
+	b.WriteString("Select files to commit:\n\n")
+
+	start, end := visibleRange(len(m.files), m.fileCursor, m.fileListHeight())
+	if start > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more", start)))
+		b.WriteString("\n")
+	}
+	for i := start; i < end; i++ {
+		f := m.files[i]
+		box := "[ ]"
+		switch f.check {
+		case fileChecked:
+			box = "[x]"
+		case filePartial:
+			box = "[~]"
+		}
+
+		line := fmt.Sprintf("%s %-8s %s", box, f.label(), f.status.Path)
+		if f.status.OrigPath != "" {
+			line = fmt.Sprintf("%s %-8s %s → %s", box, f.label(), f.status.OrigPath, f.status.Path)
+		}
+		style := normalStyle
+		prefixView := "    "
+		if i == m.fileCursor {
+			style = selectedStyle
+			prefixView = cursorStyle.Render("  > ")
+		}
+		b.WriteString(prefixView + style.Render(truncateWidth(line, contentWidth-16)))
+		b.WriteString("  " + renderFileStat(f.stat))
+		if f.check == filePartial {
+			b.WriteString(dimStyle.Render("  (partially staged)"))
+		}
+		b.WriteString("\n")
+	}
+	if end < len(m.files) {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("    ↓ %d more", len(m.files)-end)))
+		b.WriteString("\n")
+	}
+
+	if m.filesErr != nil {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render(wrapText("✗ "+m.filesErr.Error(), contentWidth)))
+		b.WriteString("\n")
+	}
+	if m.staging {
+		b.WriteString("\n" + m.spinner.View() + " Staging...")
+	} else {
+		help := "\n  ↑/↓ move • space toggle • a all/none • →/l hunks • enter stage & generate • q quit"
+		if len(m.messages) > 0 {
+			help = "\n  ↑/↓ move • space toggle • a all/none • →/l hunks • enter stage & regenerate • esc back"
+		}
+		b.WriteString(helpStyle.Render(help))
+	}
+
+	return m.renderBox(b.String())
+}
+
+// fileListHeight is the number of file rows that fit on screen.
+func (m Model) fileListHeight() int {
+	if m.height <= 0 {
+		return 15
+	}
+	return max(m.height-14, 3)
+}
+
+// visibleRange returns the window [start, end) of n rows of which height
+// fit on screen, keeping cursor visible.
+func visibleRange(n, cursor, height int) (int, int) {
+	if n <= height {
+		return 0, n
+	}
+	start := cursor - height/2
+	start = max(min(start, n-height), 0)
+	return start, start + height
+}
+
+func renderFileStat(s git.FileStat) string {
+	if s.Binary {
+		return dimStyle.Render("binary")
+	}
+	var parts []string
+	if s.Added > 0 {
+		parts = append(parts, successStyle.Render(fmt.Sprintf("+%d", s.Added)))
+	}
+	if s.Deleted > 0 {
+		parts = append(parts, errorStyle.Render(fmt.Sprintf("-%d", s.Deleted)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// truncateWidth shortens s to at most width runes, marking the cut.
+func truncateWidth(s string, width int) string {
+	r := []rune(s)
+	if width <= 1 || len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+func newFilesTestModel() Model {
+	m := newGenerationTestModel()
+	m.phase = PhaseFiles
+	m.files = []fileEntry{
+		{status: git.FileStatus{Path: "main.go", Index: ' ', Worktree: 'M'}, stat: git.FileStat{Added: 3, Deleted: 1}, check: fileChecked},
+		{status: git.FileStatus{Path: "notes.txt", Index: '?', Worktree: '?'}, stat: git.FileStat{Added: 40}},
+	}
+	return m
+}
+
+func TestFilesToggleAndSelectAll(t *testing.T) {
+	t.Parallel()
+
+	m := newFilesTestModel()
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = next.(Model)
+	if m.files[0].check != fileUnchecked {
+		t.Fatalf("space should uncheck the file under the cursor")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = next.(Model)
+	if !m.allFilesChecked() {
+		t.Fatalf("a should check all files")
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = next.(Model)
+	for _, f := range m.files {
+		if f.check != fileUnchecked {
+			t.Fatalf("second a should uncheck all files")
+		}
+	}
+}
+
+func TestFilesEmptyStagedDiffStaysOnSelection(t *testing.T) {
+	t.Parallel()
+
+	m := newFilesTestModel()
+	m.staging = true
+	next, _ := m.Update(filesStagedMsg{})
+	m = next.(Model)
+	if m.phase != PhaseFiles || m.filesErr == nil {
+		t.Fatalf("phase got %v err %v, want file selection with an error", m.phase, m.filesErr)
+	}
+
+	next, _ = m.Update(filesStagedMsg{diff: "diff --git a/main.go b/main.go", stat: "1 file changed"})
+	m = next.(Model)
+	if m.phase != PhaseLoading || !strings.HasPrefix(m.diff, "diff --git") {
+		t.Fatalf("staged diff should start generation, phase got %v", m.phase)
+	}
+}
+
+func TestVisibleRangeKeepsCursorOnScreen(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		n, cursor, height int
+		start, end        int
+	}{
+		{5, 4, 10, 0, 5},
+		{20, 0, 5, 0, 5},
+		{20, 10, 5, 8, 13},
+		{20, 19, 5, 15, 20},
+	}
+	for _, tt := range tests {
+		start, end := visibleRange(tt.n, tt.cursor, tt.height)
+		if start != tt.start || end != tt.end {
+			t.Errorf("visibleRange(%d, %d, %d) = %d, %d, want %d, %d", tt.n, tt.cursor, tt.height, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestHunkToggleAdvancesAndApplySetsFileCheck(t *testing.T) {
+	t.Parallel()
+
+	m := newFilesTestModel()
+	next, _ := m.Update(hunksLoadedMsg{
+		file: 0,
+		diff: git.FileDiff{Hunks: []git.Hunk{{Header: "@@ -1 +1 @@"}, {Header: "@@ -9 +9 @@"}}},
+		on:   []bool{true, true},
+	})
+	m = next.(Model)
+	if m.phase != PhaseHunks {
+		t.Fatalf("phase got %v want %v", m.phase, PhaseHunks)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = next.(Model)
+	if m.hunkOn[0] || m.hunkCursor != 1 {
+		t.Fatalf("space should unselect the hunk and move on, got on=%v cursor=%d", m.hunkOn, m.hunkCursor)
+	}
+	if got := hunkSelectionCheck(m.hunkOn); got != filePartial {
+		t.Fatalf("selection check got %v want partial", got)
+	}
+
+	next, _ = m.Update(hunksAppliedMsg{file: 0, check: filePartial})
+	m = next.(Model)
+	if m.phase != PhaseFiles || m.files[0].check != filePartial {
+		t.Fatalf("applied hunks should return to files with a partial check, got phase %v check %v", m.phase, m.files[0].check)
+	}
+}
+
+// gitIn runs git in dir and returns its trimmed output.
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestStageSelectionUnstagesHunksStagedAfterLoad(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q")
+	gitIn(t, dir, "config", "user.name", "Test")
+	gitIn(t, dir, "config", "user.email", "test@example.com")
+	gitIn(t, dir, "config", "commit.gpgsign", "false")
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "add", "a.txt")
+	gitIn(t, dir, "commit", "-q", "-m", "initial")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newFilesTestModel()
+	m.repo = git.Open(dir)
+	// Unstaged when the list was loaded; the hunk browser staged it later
+	// and the user then unchecked it.
+	m.files = []fileEntry{{status: git.FileStatus{Path: "a.txt", Index: ' ', Worktree: 'M'}, check: fileUnchecked}}
+	gitIn(t, dir, "add", "a.txt")
+
+	msg := m.stageSelection()().(filesStagedMsg)
+	if msg.err != nil {
+		t.Fatalf("stageSelection() error: %v", msg.err)
+	}
+	if staged := gitIn(t, dir, "diff", "--cached", "--name-only"); staged != "" {
+		t.Fatalf("unchecked file still staged: %q", staged)
+	}
+}
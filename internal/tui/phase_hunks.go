@@ -0,0 +1,249 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+// hunksLoadedMsg delivers the hunks of one file for the hunk browser.
+type hunksLoadedMsg struct {
+	file int
+	diff git.FileDiff
+	on   []bool
+	err  error
+}
+
+// hunksAppliedMsg reports the result of staging the selected hunks.
+type hunksAppliedMsg struct {
+	file  int
+	check fileCheck
+	err   error
+}
+
+// canBrowseHunks reports whether the file can be staged hunk by hunk.
+// Renames and binary files are staged as a whole.
+func (f fileEntry) canBrowseHunks() bool {
+	return f.status.OrigPath == "" && !f.stat.Binary
+}
+
+// loadHunks reads the full change of a file and marks the hunks that are
+// already staged.
+func (m Model) loadHunks(file int) tea.Cmd {
+	f := m.files[file]
+	repo := m.repo
+	return func() tea.Msg {
+		diff, err := repo.FileHunks(f.status.Path, f.status.Untracked() || f.status.Index == 'A')
+		if err != nil {
+			return hunksLoadedMsg{file: file, err: err}
+		}
+		if len(diff.Hunks) == 0 {
+			return hunksLoadedMsg{file: file, err: fmt.Errorf("%s has no hunks to select", f.status.Path)}
+		}
+
+		on := make([]bool, len(diff.Hunks))
+		switch f.check {
+		case fileChecked:
+			for i := range on {
+				on[i] = true
+			}
+		case filePartial:
+			staged, err := repo.StagedFileHunks(f.status.Path)
+			if err != nil {
+				return hunksLoadedMsg{file: file, err: err}
+			}
+			stagedChanges := make(map[string]bool, len(staged.Hunks))
+			for _, h := range staged.Hunks {
+				stagedChanges[h.Changes()] = true
+			}
+			for i, h := range diff.Hunks {
+				on[i] = stagedChanges[h.Changes()]
+			}
+		}
+		return hunksLoadedMsg{file: file, diff: diff, on: on}
+	}
+}
+
+// applyHunks resets the file in the index and applies the selected hunks.
+func (m Model) applyHunks() tea.Cmd {
+	file := m.hunkFile
+	f := m.files[file]
+	patch := m.hunkDiff.Patch(m.hunkOn)
+	check := hunkSelectionCheck(m.hunkOn)
+	repo := m.repo
+	return func() tea.Msg {
+		if err := repo.UnstagePaths(f.paths()); err != nil {
+			return hunksAppliedMsg{file: file, err: err}
+		}
+		if patch != "" {
+			if err := repo.ApplyCached(patch); err != nil {
+				return hunksAppliedMsg{file: file, err: err}
+			}
+		}
+		return hunksAppliedMsg{file: file, check: check}
+	}
+}
+
+// hunkSelectionCheck maps a hunk selection onto the file's checkbox.
+func hunkSelectionCheck(on []bool) fileCheck {
+	n := 0
+	for _, v := range on {
+		if v {
+			n++
+		}
+	}
+	switch n {
+	case 0:
+		return fileUnchecked
+	case len(on):
+		return fileChecked
+	default:
+		return filePartial
+	}
+}
+
+func (m Model) updateHunks(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case hunksAppliedMsg:
+		m.staging = false
+		m.phase = PhaseFiles
+		if msg.err != nil {
+			m.filesErr = msg.err
+			return m, nil
+		}
+		m.files[msg.file].check = msg.check
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.staging {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.hunkCursor > 0 {
+				m.hunkCursor--
+				m.hunkScroll = 0
+			}
+		case key.Matches(msg, keys.Down):
+			if m.hunkCursor < len(m.hunkDiff.Hunks)-1 {
+				m.hunkCursor++
+				m.hunkScroll = 0
+			}
+		case key.Matches(msg, keys.PageDown):
+			lines := len(m.hunkDiff.Hunks[m.hunkCursor].Lines)
+			m.hunkScroll = min(m.hunkScroll+m.hunkBodyHeight()/2, max(lines-m.hunkBodyHeight(), 0))
+		case key.Matches(msg, keys.PageUp):
+			m.hunkScroll = max(m.hunkScroll-m.hunkBodyHeight()/2, 0)
+		case key.Matches(msg, keys.Toggle):
+			m.hunkOn[m.hunkCursor] = !m.hunkOn[m.hunkCursor]
+			// Move on like "git add -p" does.
+			if m.hunkCursor < len(m.hunkDiff.Hunks)-1 {
+				m.hunkCursor++
+				m.hunkScroll = 0
+			}
+		case key.Matches(msg, keys.ToggleAll):
+			all := hunkSelectionCheck(m.hunkOn) == fileChecked
+			for i := range m.hunkOn {
+				m.hunkOn[i] = !all
+			}
+		case key.Matches(msg, keys.Enter):
+			m.staging = true
+			return m, tea.Batch(m.spinner.Tick, m.applyHunks())
+		case key.Matches(msg, keys.Escape):
+			m.phase = PhaseFiles
+		case key.Matches(msg, keys.Quit):
+			m.cancel()
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// hunkBodyHeight is the number of diff lines of the current hunk shown.
+func (m Model) hunkBodyHeight() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-16, 5)
+}
+
+func (m Model) viewHunks() string {
+	var b strings.Builder
+	contentWidth := m.contentWidth()
+	f := m.files[m.hunkFile]
+
+	b.WriteString(titleStyle.Render("🔥 fire-commit"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Select hunks of %s:\n\n", selectedStyle.Render(f.status.Path)))
+
+	selected := 0
+	for _, on := range m.hunkOn {
+		if on {
+			selected++
+		}
+	}
+	h := m.hunkDiff.Hunks[m.hunkCursor]
+	box := "[ ]"
+	if m.hunkOn[m.hunkCursor] {
+		box = "[x]"
+	}
+	b.WriteString(fmt.Sprintf("  %s Hunk %d/%d  ", box, m.hunkCursor+1, len(m.hunkDiff.Hunks)))
+	b.WriteString(hunkHeaderStyle.Render(truncateWidth(h.Header, contentWidth-20)))
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(fmt.Sprintf("  %d of %d hunks staged", selected, len(m.hunkDiff.Hunks))))
+	b.WriteString("\n\n")
+
+	height := m.hunkBodyHeight()
+	end := min(m.hunkScroll+height, len(h.Lines))
+	if m.hunkScroll > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ↑ %d more lines", m.hunkScroll)))
+		b.WriteString("\n")
+	}
+	for _, line := range h.Lines[m.hunkScroll:end] {
+		b.WriteString("  ")
+		b.WriteString(renderDiffLine(truncateWidth(expandTabs(line), contentWidth-2)))
+		b.WriteString("\n")
+	}
+	if end < len(h.Lines) {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ↓ %d more lines (pgdn)", len(h.Lines)-end)))
+		b.WriteString("\n")
+	}
+
+	if m.staging {
+		b.WriteString("\n" + m.spinner.View() + " Staging hunks...")
+	} else {
+		b.WriteString(helpStyle.Render("\n  ↑/↓ hunk • space toggle • a all/none • pgup/pgdn scroll • enter stage • esc discard"))
+	}
+
+	return m.renderBox(b.String())
+}
+
+// renderDiffLine colors one line of a unified diff by its prefix.
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return hunkHeaderStyle.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return addedLineStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return removedLineStyle.Render(line)
+	case strings.HasPrefix(line, `\`):
+		return dimStyle.Render(line)
+	default:
+		return normalStyle.Render(line)
+	}
+}
+
+// expandTabs replaces tabs so that line widths are predictable.
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
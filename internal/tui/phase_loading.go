@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/llm"
 )
 
 func (m Model) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -23,6 +24,8 @@ func (m Model) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.phase = PhaseSelect
 				return m, nil
 			}
+		case key.Matches(msg, keys.Diff):
+			return m.openDiff(), nil
 		case key.Matches(msg, keys.Quit):
 			m.cancel()
 			return m, tea.Quit
@@ -43,13 +46,22 @@ func (m Model) viewLoading() string {
 
 	b.WriteString(fmt.Sprintf("%s Generating commit messages (%d/%d finished, %d ready)\n",
 		m.spinner.View(), m.finished, m.total, m.completed))
+	if m.lastFeedback != "" {
+		b.WriteString(dimStyle.Render(wrapText("Refining: "+m.lastFeedback, contentWidth)))
+		b.WriteString("\n")
+	}
 
+	structured := m.cfg.Generation.StructuredOutput && m.cfg.Generation.Convention != llm.ConventionPlain
 	for i := 0; i < m.total; i++ {
-		preview := compactPreview(m.partial[i])
+		raw := m.partial[i]
+		if !m.slotDone[i] {
+			raw = llm.StreamPreview(raw, structured)
+		}
+		preview := compactPreview(raw)
 		switch {
 		case m.slotFailed[i]:
 			b.WriteString("\n")
-			b.WriteString(renderWrappedLine("  ✗ ", "  "+errorStyle.Render("✗")+" ", "request failed", dimStyle, contentWidth))
+			b.WriteString(renderWrappedLine("  ✗ ", "  "+errorStyle.Render("✗")+" ", slotFailureReason(m.slotErr[i]), dimStyle, contentWidth))
 		case m.slotDone[i]:
 			b.WriteString("\n")
 			if preview == "" {
@@ -59,6 +71,10 @@ func (m Model) viewLoading() string {
 		case preview != "":
 			b.WriteString("\n")
 			b.WriteString(renderWrappedLine("  ~ ", "  "+selectedStyle.Render("~")+" ", preview, dimStyle, contentWidth))
+		case m.partial[i] != "":
+			// Structured output has not reached the description yet.
+			b.WriteString("\n")
+			b.WriteString(renderWrappedLine("  ~ ", "  "+selectedStyle.Render("~")+" ", "(writing suggestion...)", dimStyle, contentWidth))
 		default:
 			b.WriteString("\n")
 			b.WriteString(renderWrappedLine("    ", "    ", "...", dimStyle, contentWidth))
@@ -71,9 +87,9 @@ func (m Model) viewLoading() string {
 	}
 
 	if len(m.messages) > 0 {
-		b.WriteString(helpStyle.Render("\n\n  enter select ready messages • q quit"))
+		b.WriteString(helpStyle.Render("\n\n  enter select ready messages • d diff • q quit"))
 	} else {
-		b.WriteString(helpStyle.Render("\n\n  q quit"))
+		b.WriteString(helpStyle.Render("\n\n  d diff • q quit"))
 	}
 
 	return m.renderBox(b.String())
@@ -86,3 +102,11 @@ func compactPreview(s string) string {
 	oneLine := strings.ReplaceAll(s, "\n", " ")
 	return strings.Join(strings.Fields(oneLine), " ")
 }
+
+// slotFailureReason describes why a generation slot produced no suggestion.
+func slotFailureReason(err error) string {
+	if err == nil {
+		return "request failed"
+	}
+	return "request failed: " + err.Error()
+}
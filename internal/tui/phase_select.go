@@ -7,9 +7,14 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/llm"
 )
 
 func (m Model) updateSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.refining {
+		return m.updateRefineInput(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
@@ -25,7 +30,7 @@ func (m Model) updateSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if len(m.messages) == 0 {
 				return m, nil
 			}
-			m.confirmCursor = confirmCommitOnly
+			m.confirmCursor = m.defaultConfirmCursor()
 			m.phase = PhaseConfirm
 			return m, nil
 		case key.Matches(msg, keys.Edit):
@@ -36,6 +41,31 @@ func (m Model) updateSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.editing = true
 			m.phase = PhaseEdit
 			return m, m.editArea.Focus()
+		case key.Matches(msg, keys.Refine):
+			if len(m.messages) == 0 {
+				return m, nil
+			}
+			m.refining = true
+			m.refineInput.SetValue("")
+			m.refineInput.Focus()
+			return m, m.refineInput.Cursor.BlinkCmd()
+		case key.Matches(msg, keys.Stage):
+			if m.amend {
+				return m, nil
+			}
+			files, err := loadFileEntries(m.repo)
+			if err != nil {
+				m.commitErr = err
+				m.phase = PhaseDone
+				return m, nil
+			}
+			m.files = files
+			m.fileCursor = 0
+			m.filesErr = nil
+			m.phase = PhaseFiles
+			return m, nil
+		case key.Matches(msg, keys.Diff):
+			return m.openDiff(), nil
 		case key.Matches(msg, keys.Regen):
 			m.resetForRegeneration()
 			m.phase = PhaseLoading
@@ -48,20 +78,75 @@ func (m Model) updateSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateRefineInput handles the one-line feedback input. Submitting it adds
+// the selected candidate and the feedback to the conversation and starts a
+// new generation round.
+func (m Model) updateRefineInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEnter:
+			feedback := strings.TrimSpace(m.refineInput.Value())
+			m.refining = false
+			m.refineInput.Blur()
+			if feedback == "" || len(m.messages) == 0 {
+				return m, nil
+			}
+			m.conversation = append(m.conversation, llm.RefinementMessages(m.messages[m.cursor], feedback)...)
+			m.lastFeedback = feedback
+			m.resetForRegeneration()
+			m.phase = PhaseLoading
+			return m, tea.Batch(m.spinner.Tick, m.startGeneration())
+		case tea.KeyEscape:
+			m.refining = false
+			m.refineInput.Blur()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.refineInput, cmd = m.refineInput.Update(msg)
+	return m, cmd
+}
+
 func (m Model) viewSelect() string {
 	var b strings.Builder
 	contentWidth := m.contentWidth()
 
 	b.WriteString(titleStyle.Render("🔥 fire-commit"))
 	b.WriteString("\n\n")
-	b.WriteString("Select a commit message:\n\n")
+	b.WriteString("Select a commit message:\n")
+	if m.lastFeedback != "" {
+		b.WriteString(dimStyle.Render(wrapText("Refined: "+m.lastFeedback, contentWidth)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	for i, msg := range m.messages {
+		header := firstLine(msg)
 		if i == m.cursor {
-			b.WriteString(renderWrappedLine("  > ", cursorStyle.Render("  > "), msg, selectedStyle, contentWidth))
+			b.WriteString(renderWrappedLine("  > ", cursorStyle.Render("  > "), header, selectedStyle, contentWidth))
 		} else {
-			b.WriteString(renderWrappedLine("    ", "    ", msg, normalStyle, contentWidth))
+			b.WriteString(renderWrappedLine("    ", "    ", header, normalStyle, contentWidth))
+		}
+		b.WriteString("\n")
+	}
+
+	if n := m.offlineCount(); n > 0 {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render(wrapText(fmt.Sprintf("Provider unreachable: %d of these suggestion(s) come from the offline heuristic, not the model.", n), contentWidth)))
+		b.WriteString("\n")
+	}
+
+	if m.cursor < len(m.suggestions) {
+		b.WriteString(m.viewSuggestionDetails(m.suggestions[m.cursor], contentWidth))
+	}
+
+	for i, err := range m.slotErr {
+		if m.slotFailed[i] {
+			b.WriteString("\n")
+			b.WriteString(renderWrappedLine("  ✗ ", "  "+errorStyle.Render("✗")+" ", slotFailureReason(err), dimStyle, contentWidth))
 		}
+	}
+	if m.failed > 0 {
 		b.WriteString("\n")
 	}
 
@@ -70,11 +155,83 @@ func (m Model) viewSelect() string {
 		b.WriteString(dimStyle.Render(fmt.Sprintf("Generating %d more suggestion(s) in background...", pending)))
 	}
 
-	b.WriteString(helpStyle.Render("\n  ↑/↓/j/k select • enter confirm • e edit • r regen • q quit"))
+	if m.usage.CacheReadTokens > 0 || m.usage.CacheCreationTokens > 0 {
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render(fmt.Sprintf("Prompt cache: %d tokens read, %d written",
+			m.usage.CacheReadTokens, m.usage.CacheCreationTokens)))
+	}
+
+	if m.refining {
+		b.WriteString("\n\nFeedback: ")
+		b.WriteString(m.refineInput.View())
+		b.WriteString(helpStyle.Render("\n  enter refine • esc cancel"))
+	} else {
+		help := "\n  ↑/↓/j/k select • enter confirm • e edit • f refine • r regen • s stage • d diff • q quit"
+		if m.amend {
+			help = "\n  ↑/↓/j/k select • enter confirm • e edit • f refine • r regen • d diff • q quit"
+		}
+		b.WriteString(helpStyle.Render(help))
+	}
 
 	return m.renderBox(b.String())
 }
 
+// viewSuggestionDetails renders type/scope chips, the body and the model's
+// rationale for the highlighted suggestion.
+func (m Model) viewSuggestionDetails(s llm.Suggestion, width int) string {
+	var b strings.Builder
+
+	var chips []string
+	if s.Type != "" {
+		chips = append(chips, chipStyle.Render(s.Type))
+	}
+	if s.Scope != "" {
+		chips = append(chips, chipStyle.Render(s.Scope))
+	}
+	if s.Breaking {
+		chips = append(chips, breakingChipStyle.Render("breaking"))
+	}
+	if s.Offline {
+		chips = append(chips, breakingChipStyle.Render("offline heuristic"))
+	}
+	if len(chips) > 0 {
+		b.WriteString("\n  ")
+		b.WriteString(strings.Join(chips, " "))
+		b.WriteString("\n")
+	}
+
+	if body := strings.TrimSpace(s.Body); body != "" {
+		b.WriteString("\n")
+		b.WriteString(renderWrappedLine("  ", "  ", body, dimStyle, width))
+		b.WriteString("\n")
+	}
+	if s.Rationale != "" {
+		b.WriteString("\n")
+		b.WriteString(renderWrappedLine("  why: ", dimStyle.Render("  why: "), s.Rationale, dimStyle, width))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// offlineCount returns how many ready suggestions came from the offline
+// heuristic.
+func (m Model) offlineCount() int {
+	n := 0
+	for _, s := range m.suggestions {
+		if s.Offline {
+			n++
+		}
+	}
+	return n
+}
+
+func firstLine(s string) string {
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx]
+	}
+	return s
+}
+
 func (m *Model) resetForRegeneration() {
 	n := m.cfg.Generation.NumSuggestions
 	if n <= 0 {
@@ -88,19 +245,22 @@ func (m *Model) resetForRegeneration() {
 	m.generationID++
 
 	m.messages = make([]string, 0, n)
+	m.suggestions = make([]llm.Suggestion, 0, n)
 	m.partial = make([]string, n)
 	m.slotDone = make([]bool, n)
 	m.slotFailed = make([]bool, n)
+	m.slotErr = make([]error, n)
 	m.completed = 0
 	m.finished = 0
 	m.failed = 0
 	m.total = n
 	m.resultCh = nil
+	m.usage = llm.Usage{}
 	m.cursor = 0
 	m.editing = false
 	m.editArea.Blur()
 
-	m.confirmCursor = confirmCommitOnly
+	m.confirmCursor = m.defaultConfirmCursor()
 	m.versionTag = ""
 	m.editingTag = false
 	m.tagInput.SetValue("")
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+// pushProgressBuffer is how many progress lines may queue up before older
+// ones are dropped; the final result is always delivered.
+const pushProgressBuffer = 32
+
+// gitPushOptions builds the push for the current branch: to its upstream,
+// or to the chosen remote with -u when there is none. An amend of a pushed
+// commit needs --force-with-lease.
+func (m Model) gitPushOptions() git.PushOptions {
+	opts := git.PushOptions{
+		ForceWithLease: m.amend && m.headPushed,
+		Options:        m.pushOptions,
+	}
+	if m.pushTarget.Upstream == "" && m.pushRemote != "" && m.pushTarget.Branch != "" {
+		opts.Remote = m.pushRemote
+		opts.Branch = m.pushTarget.Branch
+		opts.SetUpstream = true
+	}
+	return opts
+}
+
+// pushDestination names where the branch is pushed, e.g. "origin/main".
+func (m Model) pushDestination() string {
+	if m.pushTarget.Upstream != "" {
+		return m.pushTarget.Upstream
+	}
+	return m.pushRemote + "/" + m.pushTarget.Branch
+}
+
+// tagRemote is the remote a new tag is pushed to.
+func (m Model) tagRemote() string {
+	switch {
+	case m.pushTarget.UpstreamRemote != "":
+		return m.pushTarget.UpstreamRemote
+	case m.pushRemote != "":
+		return m.pushRemote
+	}
+	return "origin"
+}
+
+// nextPushRemote cycles the remote used for a branch without upstream.
+func (m *Model) nextPushRemote() {
+	remotes := m.pushTarget.Remotes
+	if m.pushTarget.Upstream != "" || len(remotes) < 2 {
+		return
+	}
+	i := slices.Index(remotes, m.pushRemote)
+	m.pushRemote = remotes[(i+1)%len(remotes)]
+}
+
+// viewPushPlan describes the push on the confirm screen.
+func (m Model) viewPushPlan(width int) string {
+	var b strings.Builder
+	switch {
+	case m.pushTarget.Upstream != "":
+		b.WriteString("Push to: " + selectedStyle.Render(m.pushTarget.Upstream))
+	case m.pushRemote == "":
+		return errorStyle.Render(wrapText("⚠ No remote configured; the push will fail.", width))
+	default:
+		b.WriteString("Push to: " + selectedStyle.Render(m.pushDestination()))
+		b.WriteString(dimStyle.Render("  (sets upstream with -u)"))
+	}
+
+	opts := m.gitPushOptions()
+	var flags []string
+	if opts.ForceWithLease {
+		flags = append(flags, "--force-with-lease")
+	}
+	for _, o := range opts.Options {
+		flags = append(flags, "-o "+o)
+	}
+	if len(flags) > 0 {
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render(wrapText("  "+strings.Join(flags, " "), width)))
+	}
+	return b.String()
+}
+
+func (m Model) doGitPush() tea.Cmd {
+	opts := m.gitPushOptions()
+	repo := m.repo
+	return func() tea.Msg {
+		ch := make(chan tea.Msg, pushProgressBuffer)
+		go func() {
+			err := repo.PushWith(opts, func(line string) {
+				select {
+				case ch <- pushProgressMsg{line: line}:
+				default:
+				}
+			})
+			ch <- pushDoneMsg{err: err}
+			close(ch)
+		}()
+		return pushStartedMsg{ch: ch}
+	}
+}
+
+// waitForPush reads the next progress line or the result of the push.
+func waitForPush(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// pushProgressLine is the latest progress line, for the committing view.
+func (m Model) pushProgressLine(width int) string {
+	if m.pushProgress == "" {
+		return ""
+	}
+	return "\n" + dimStyle.Render("  "+truncateWidth(m.pushProgress, width-2))
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+func TestPushSetsUpstreamOnChosenRemote(t *testing.T) {
+	t.Parallel()
+
+	m := newConfirmTestModel(config.DefaultConfig())
+	m.pushTarget = git.PushTarget{Branch: "feature", Remotes: []string{"fork", "origin"}}
+	m.pushRemote = m.pushTarget.DefaultRemote("")
+	m.confirmCursor = confirmCommitAndPush
+
+	opts := m.gitPushOptions()
+	if opts.Remote != "origin" || opts.Branch != "feature" || !opts.SetUpstream {
+		t.Fatalf("push options got %+v", opts)
+	}
+	if !strings.Contains(m.viewConfirm(), "u remote") {
+		t.Fatalf("confirm view should offer to change the remote")
+	}
+
+	m = pressConfirm(m, "u")
+	if m.pushRemote != "fork" || m.pushDestination() != "fork/feature" {
+		t.Fatalf("u should switch to the next remote, got %q", m.pushRemote)
+	}
+}
+
+func TestPushToUpstreamWithLeaseAfterAmend(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultConfig()
+	cfg.Push.Options = []string{"ci.skip"}
+	m := newConfirmTestModel(cfg)
+	m.pushTarget = git.PushTarget{Branch: "main", Upstream: "origin/main", UpstreamRemote: "origin", Remotes: []string{"origin"}}
+	m.pushOptions = slices.Clone(cfg.Push.Options)
+	m.amend = true
+	m.headPushed = true
+
+	opts := m.gitPushOptions()
+	if opts.Remote != "" || opts.SetUpstream || !opts.ForceWithLease || !slices.Equal(opts.Options, []string{"ci.skip"}) {
+		t.Fatalf("push options got %+v", opts)
+	}
+	m.confirmCursor = confirmCommitAndPush
+	view := m.viewConfirm()
+	if !strings.Contains(view, "--force-with-lease") || !strings.Contains(view, "-o ci.skip") {
+		t.Fatalf("confirm view should show the push flags:\n%s", view)
+	}
+}
+
+func TestCommittingStreamsPushProgress(t *testing.T) {
+	t.Parallel()
+
+	m := newConfirmTestModel(config.DefaultConfig())
+	m.phase = PhaseCommitting
+	m.committed = true
+	m.wantPush = true
+	m.pushTarget = git.PushTarget{Branch: "main", Upstream: "origin/main"}
+
+	ch := make(chan tea.Msg, 1)
+	next, cmd := m.updateCommitting(pushStartedMsg{ch: ch})
+	m = next.(Model)
+	if cmd == nil || m.pushCh == nil {
+		t.Fatalf("push start should wait for progress")
+	}
+
+	next, _ = m.updateCommitting(pushProgressMsg{line: "Writing objects:  50% (1/2)"})
+	m = next.(Model)
+	if !strings.Contains(m.viewCommitting(), "Writing objects:  50% (1/2)") {
+		t.Fatalf("committing view should show the progress line")
+	}
+
+	next, _ = m.updateCommitting(pushDoneMsg{err: errors.New("git push: rejected")})
+	m = next.(Model)
+	if m.phase != PhaseDone || m.pushErr == nil || m.pushCh != nil {
+		t.Fatalf("push result got phase=%v err=%v", m.phase, m.pushErr)
+	}
+}
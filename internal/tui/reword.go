@@ -0,0 +1,398 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+	"github.com/lieyanc/fire-commit/internal/llm"
+)
+
+// RewordCommit is one commit offered for rewording.
+type RewordCommit struct {
+	Hash      string
+	ShortHash string
+	Message   string
+	// Diff is the patch the commit introduces.
+	Diff string
+}
+
+// rewordRow is the review state of one commit.
+type rewordRow struct {
+	commit RewordCommit
+	// message is the generated or edited replacement.
+	message string
+	err     error
+	ready   bool
+	accept  bool
+	// generation identifies the active request for the row so results of a
+	// superseded regeneration are ignored.
+	generation int
+}
+
+// rewordResultMsg delivers the generated message for one row.
+type rewordResultMsg struct {
+	index      int
+	generation int
+	message    string
+	err        error
+}
+
+// defaultRewordConcurrency bounds parallel requests when the config does not.
+const defaultRewordConcurrency = 4
+
+// RewordModel is the review table of "firecommit reword".
+type RewordModel struct {
+	cfg  *config.Config
+	opts llm.GenerateOptions
+	rows []rewordRow
+
+	cursor     int
+	spinner    spinner.Model
+	editArea   textarea.Model
+	editing    bool
+	confirming bool
+	applied    bool
+
+	// sem bounds concurrent generation requests across rows.
+	sem    chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	width  int
+	height int
+}
+
+// NewRewordModel creates the review table for commits of repo, oldest first.
+func NewRewordModel(cfg *config.Config, repo *git.Repo, commits []RewordCommit) RewordModel {
+	ta := textarea.New()
+	ta.Placeholder = "Edit commit message..."
+	ta.CharLimit = 2000
+	ta.SetWidth(60)
+	ta.SetHeight(5)
+
+	limit := cfg.Generation.MaxConcurrency
+	if limit <= 0 {
+		limit = defaultRewordConcurrency
+	}
+
+	rows := make([]rewordRow, len(commits))
+	hashes := make([]string, len(commits))
+	for i, c := range commits {
+		rows[i] = rewordRow{commit: c, generation: 1}
+		hashes[i] = c.Hash
+	}
+
+	// The messages being replaced are not shown to the model as examples.
+	opts := GenerateOptions(cfg, repo)
+	if n := cfg.Generation.HistoryExamples; n > 0 {
+		opts.History, _ = repo.RecentSubjectsExcept(n, hashes)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return RewordModel{
+		cfg:      cfg,
+		opts:     opts,
+		rows:     rows,
+		spinner:  newSpinner(),
+		editArea: ta,
+		sem:      make(chan struct{}, limit),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (m RewordModel) Init() tea.Cmd {
+	cmds := []tea.Cmd{m.spinner.Tick}
+	for i := range m.rows {
+		cmds = append(cmds, m.generateRow(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// generateRow requests one suggestion for the commit's own diff.
+func (m RewordModel) generateRow(i int) tea.Cmd {
+	cfg, opts, ctx, sem := m.cfg, m.opts, m.ctx, m.sem
+	row := m.rows[i]
+	return func() tea.Msg {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-ctx.Done():
+			return rewordResultMsg{index: i, generation: row.generation, err: ctx.Err()}
+		}
+
+		provider, err := llm.NewProvider(cfg)
+		if err != nil {
+			return rewordResultMsg{index: i, generation: row.generation, err: err}
+		}
+		result := rewordResultMsg{index: i, generation: row.generation, err: llm.ErrEmptyResponse}
+		for ev := range llm.GenerateMultiple(ctx, provider, row.commit.Diff, opts, 1) {
+			switch {
+			case ev.Err != nil:
+				result.err = ev.Err
+			case ev.Done:
+				if msg := ev.Suggestion.Message(); msg != "" {
+					result.message, result.err = msg, nil
+				}
+			}
+		}
+		return result
+	}
+}
+
+func (m RewordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.editArea.SetWidth(max(contentWidthFor(m.width)-4, minInputWidth))
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case rewordResultMsg:
+		row := &m.rows[msg.index]
+		if msg.generation != row.generation {
+			return m, nil
+		}
+		row.ready = true
+		row.err = msg.err
+		if msg.err == nil {
+			row.message = msg.message
+			row.accept = true
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.CtrlC) {
+			m.cancel()
+			return m, tea.Quit
+		}
+		switch {
+		case m.editing:
+			return m.updateRewordEdit(msg)
+		case m.confirming:
+			return m.updateRewordConfirm(msg)
+		}
+		return m.updateRewordTable(msg)
+	}
+
+	if m.editing {
+		var cmd tea.Cmd
+		m.editArea, cmd = m.editArea.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m RewordModel) updateRewordTable(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	row := &m.rows[m.cursor]
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	case key.Matches(msg, keys.Accept):
+		if row.ready && row.message != "" {
+			row.accept = true
+		}
+	case key.Matches(msg, keys.Skip):
+		row.accept = false
+	case key.Matches(msg, keys.Edit):
+		value := row.message
+		if value == "" {
+			value = row.commit.Message
+		}
+		m.editArea.SetValue(value)
+		m.editing = true
+		return m, m.editArea.Focus()
+	case key.Matches(msg, keys.Regen):
+		row.generation++
+		row.ready = false
+		row.accept = false
+		row.err = nil
+		row.message = ""
+		return m, m.generateRow(m.cursor)
+	case key.Matches(msg, keys.Enter):
+		if m.pendingRows() == 0 && m.acceptedRows() > 0 {
+			m.confirming = true
+		}
+	case key.Matches(msg, keys.Quit):
+		m.cancel()
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m RewordModel) updateRewordEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Escape):
+		m.editing = false
+		m.editArea.Blur()
+		return m, nil
+	case key.Matches(msg, keys.Save):
+		if value := strings.TrimSpace(m.editArea.Value()); value != "" {
+			row := &m.rows[m.cursor]
+			// Drop any request still running for the row.
+			row.generation++
+			row.message = value
+			row.err = nil
+			row.ready = true
+			row.accept = true
+		}
+		m.editing = false
+		m.editArea.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.editArea, cmd = m.editArea.Update(msg)
+	return m, cmd
+}
+
+func (m RewordModel) updateRewordConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		m.applied = true
+		m.cancel()
+		return m, tea.Quit
+	case "n", "esc":
+		m.confirming = false
+	case "q":
+		m.cancel()
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m RewordModel) pendingRows() int {
+	n := 0
+	for _, r := range m.rows {
+		if !r.ready {
+			n++
+		}
+	}
+	return n
+}
+
+func (m RewordModel) acceptedRows() int {
+	n := 0
+	for _, r := range m.rows {
+		if r.accept {
+			n++
+		}
+	}
+	return n
+}
+
+func (m RewordModel) View() string {
+	var b strings.Builder
+	contentWidth := contentWidthFor(m.width)
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("🔥 fire-commit reword — %d commits", len(m.rows))))
+	b.WriteString("\n\n")
+
+	if m.editing {
+		b.WriteString(fmt.Sprintf("Edit message for %s:\n\n", m.rows[m.cursor].commit.ShortHash))
+		b.WriteString(m.editArea.View())
+		b.WriteString(helpStyle.Render("\n\n  ctrl+s save • esc cancel"))
+		return renderBoxWidth(b.String(), m.width)
+	}
+
+	for i, row := range m.rows {
+		prefix, prefixView := "    ", "    "
+		if i == m.cursor {
+			prefix, prefixView = "  > ", cursorStyle.Render("  > ")
+		}
+
+		var mark string
+		switch {
+		case !row.ready:
+			mark = m.spinner.View()
+		case row.accept:
+			mark = successStyle.Render("✓")
+		case row.err != nil:
+			mark = errorStyle.Render("✗")
+		default:
+			mark = dimStyle.Render("–")
+		}
+
+		old, _, _ := strings.Cut(row.commit.Message, "\n")
+		b.WriteString(prefixView + mark + " " + dimStyle.Render(row.commit.ShortHash) + "  ")
+		b.WriteString(normalStyle.Render(old))
+		b.WriteString("\n")
+
+		indent := prefix + "  " + strings.Repeat(" ", len(row.commit.ShortHash)+2)
+		var next string
+		style := highlightStyle
+		switch {
+		case !row.ready:
+			next, style = "generating...", dimStyle
+		case row.err != nil && row.message == "":
+			next, style = "failed: "+slotFailureReason(row.err), errorStyle
+		default:
+			next, _, _ = strings.Cut(row.message, "\n")
+			if !row.accept {
+				style = dimStyle
+				next += "  (skipped)"
+			}
+		}
+		b.WriteString(renderWrappedLine(indent+"→ ", indent+"→ ", next, style, contentWidth))
+		b.WriteString("\n")
+	}
+
+	switch {
+	case m.confirming:
+		b.WriteString("\n")
+		b.WriteString(selectedStyle.Render(fmt.Sprintf("Rewrite %d of %d commit messages? (y/n)", m.acceptedRows(), len(m.rows))))
+		b.WriteString(helpStyle.Render("\n  y/enter rewrite history • n/esc back • q quit"))
+	case m.pendingRows() > 0:
+		b.WriteString(helpStyle.Render(fmt.Sprintf("\n  waiting for %d suggestion(s) • ↑/↓ move • a accept • s skip • e edit • r regenerate • q quit", m.pendingRows())))
+	default:
+		b.WriteString(helpStyle.Render("\n  ↑/↓ move • a accept • s skip • e edit • r regenerate • enter apply • q quit"))
+	}
+
+	return renderBoxWidth(b.String(), m.width)
+}
+
+// Messages returns the accepted replacements keyed by commit hash, or nil
+// when the user quit without applying.
+func (m RewordModel) Messages() map[string]string {
+	if !m.applied {
+		return nil
+	}
+	messages := make(map[string]string)
+	for _, r := range m.rows {
+		if r.accept {
+			messages[r.commit.Hash] = r.message
+		}
+	}
+	return messages
+}
+
+// RunReword shows the review table and returns the accepted messages keyed
+// by commit hash, or nil when the user cancelled.
+func RunReword(cfg *config.Config, repo *git.Repo, commits []RewordCommit) (map[string]string, error) {
+	if err := resolveCredentials(cfg); err != nil {
+		return nil, err
+	}
+	p := tea.NewProgram(NewRewordModel(cfg, repo, commits), tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	return final.(RewordModel).Messages(), nil
+}
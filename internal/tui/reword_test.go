@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+func newRewordTestModel() RewordModel {
+	return NewRewordModel(config.DefaultConfig(), git.Open(""), []RewordCommit{
+		{Hash: "aaa", ShortHash: "aaa", Message: "wip"},
+		{Hash: "bbb", ShortHash: "bbb", Message: "wip 2"},
+	})
+}
+
+func updateReword(t *testing.T, m RewordModel, msg tea.Msg) RewordModel {
+	t.Helper()
+	next, _ := m.Update(msg)
+	return next.(RewordModel)
+}
+
+func TestRewordAcceptsGeneratedAndSkipsFailed(t *testing.T) {
+	t.Parallel()
+
+	m := newRewordTestModel()
+	m = updateReword(t, m, rewordResultMsg{index: 0, generation: 1, message: "feat: add api"})
+	m = updateReword(t, m, rewordResultMsg{index: 1, generation: 1, err: errors.New("boom")})
+
+	if m.pendingRows() != 0 {
+		t.Fatalf("pending got %d want 0", m.pendingRows())
+	}
+	m = updateReword(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.confirming {
+		t.Fatalf("enter should ask for confirmation once all rows are ready")
+	}
+	m = updateReword(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+
+	got := m.Messages()
+	if len(got) != 1 || got["aaa"] != "feat: add api" {
+		t.Fatalf("messages got %v", got)
+	}
+}
+
+func TestRewordIgnoresStaleResultsAfterRegenerate(t *testing.T) {
+	t.Parallel()
+
+	m := newRewordTestModel()
+	m = updateReword(t, m, rewordResultMsg{index: 0, generation: 1, message: "feat: first"})
+	m = updateReword(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = updateReword(t, m, rewordResultMsg{index: 0, generation: 1, message: "feat: stale"})
+
+	if m.rows[0].ready {
+		t.Fatalf("stale result must not complete the regenerated row")
+	}
+	m = updateReword(t, m, rewordResultMsg{index: 0, generation: 2, message: "feat: second"})
+	if m.rows[0].message != "feat: second" || !m.rows[0].accept {
+		t.Fatalf("row got %+v", m.rows[0])
+	}
+}
+
+func TestRewordSkipAndQuitReturnNothing(t *testing.T) {
+	t.Parallel()
+
+	m := newRewordTestModel()
+	m = updateReword(t, m, rewordResultMsg{index: 0, generation: 1, message: "feat: a"})
+	m = updateReword(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if m.rows[0].accept {
+		t.Fatalf("s should skip the row")
+	}
+	m = updateReword(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if m.Messages() != nil {
+		t.Fatalf("quitting must not return messages")
+	}
+}
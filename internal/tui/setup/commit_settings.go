@@ -0,0 +1,124 @@
+package setup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/lieyanc/fire-commit/internal/config"
+)
+
+// commitSummary describes the commit settings for the main menu.
+func commitSummary(cfg *config.Config) string {
+	parts := []string{"staging: " + cfg.Commit.Staging()}
+	if cfg.Commit.Sign {
+		parts = append(parts, "sign")
+	}
+	if cfg.Commit.Signoff {
+		parts = append(parts, "signoff")
+	}
+	if cfg.Commit.NoVerify {
+		parts = append(parts, "no-verify")
+	}
+	if n := len(cfg.Commit.CoAuthors) + len(cfg.Commit.Reviewers) + len(cfg.Commit.Trailers); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d trailer(s)", n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// editCommitSettings runs the commit settings form. It modifies cfg in-place.
+func editCommitSettings(cfg *config.Config) error {
+	staging := cfg.Commit.Staging()
+	sign := cfg.Commit.Sign
+	signoff := cfg.Commit.Signoff
+	noVerify := cfg.Commit.NoVerify
+	coAuthors := strings.Join(cfg.Commit.CoAuthors, "\n")
+	reviewers := strings.Join(cfg.Commit.Reviewers, "\n")
+	trailers := strings.Join(cfg.Commit.Trailers, "\n")
+
+	stagingSelect := huh.NewSelect[string]().
+		Title("When changes are not staged").
+		Options(stagingOptions()...).
+		Value(&staging)
+
+	flags := huh.NewGroup(
+		huh.NewConfirm().
+			Title("Sign commits (git commit -S)?").
+			Description("Uses the GPG or SSH key configured in git.").
+			Value(&sign),
+		huh.NewConfirm().
+			Title("Add Signed-off-by (--signoff)?").
+			Value(&signoff),
+		huh.NewConfirm().
+			Title("Skip pre-commit and commit-msg hooks (--no-verify)?").
+			Value(&noVerify),
+	)
+
+	people := huh.NewGroup(
+		huh.NewText().
+			Title("Saved co-authors").
+			Description("One \"Name <email>\" per line, offered as Co-authored-by on the confirm screen (t).").
+			Value(&coAuthors),
+		huh.NewText().
+			Title("Saved reviewers").
+			Description("One \"Name <email>\" per line, offered as Reviewed-by.").
+			Value(&reviewers),
+		huh.NewText().
+			Title("Trailers added to every commit").
+			Description("One \"Key: value\" per line.").
+			Value(&trailers).
+			Validate(validateTrailers),
+	)
+
+	if err := huh.NewForm(huh.NewGroup(stagingSelect), flags, people).Run(); err != nil {
+		return err
+	}
+
+	cfg.Commit.StagingPolicy = staging
+	cfg.Commit.Sign = sign
+	cfg.Commit.Signoff = signoff
+	cfg.Commit.NoVerify = noVerify
+	cfg.Commit.CoAuthors = splitLines(coAuthors)
+	cfg.Commit.Reviewers = splitLines(reviewers)
+	cfg.Commit.Trailers = splitLines(trailers)
+	return nil
+}
+
+func stagingOptions() []huh.Option[string] {
+	return []huh.Option[string]{
+		huh.NewOption("Ask which files to stage (default)", config.StagingAsk),
+		huh.NewOption("Stage everything, including new files (git add -A)", config.StagingAll),
+		huh.NewOption("Stage tracked files only (git add -u)", config.StagingTracked),
+		huh.NewOption("Never stage; commit only what is already staged", config.StagingStagedOnly),
+	}
+}
+
+func syncOptions() []huh.Option[string] {
+	return []huh.Option[string]{
+		huh.NewOption("Rebase onto the upstream (default)", config.SyncRebase),
+		huh.NewOption("Merge the upstream", config.SyncMerge),
+		huh.NewOption("Push without fetching first", config.SyncOff),
+	}
+}
+
+// validateTrailers checks that every line has the "Key: value" form.
+func validateTrailers(s string) error {
+	for _, line := range splitLines(s) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" || strings.ContainsAny(strings.TrimSpace(key), " \t") || strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%q is not a \"Key: value\" trailer", line)
+		}
+	}
+	return nil
+}
+
+// splitLines returns the non-empty, trimmed lines of s.
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -1,8 +1,11 @@
 package setup
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/lieyanc/fire-commit/internal/config"
@@ -20,13 +23,12 @@ func RunConfigEditor(cfg *config.Config) (*config.Config, error) {
 	for {
 		var choice string
 
-		displayNames := llm.ProviderDisplayNames()
 		providerSummary := "not configured"
 		if cfg.DefaultProvider != "" {
-			if p, ok := cfg.Providers[cfg.DefaultProvider]; ok {
-				providerSummary = fmt.Sprintf("%s / %s", displayNames[cfg.DefaultProvider], p.Model)
-			} else {
-				providerSummary = displayNames[cfg.DefaultProvider]
+			p, ok := cfg.Providers[cfg.DefaultProvider]
+			providerSummary = llm.ProviderDisplayName(cfg.DefaultProvider, p)
+			if ok && p.Model != "" {
+				providerSummary += " / " + p.Model
 			}
 		}
 		genSummary := fmt.Sprintf("%s, %d suggestions, %d lines",
@@ -50,7 +52,9 @@ func RunConfigEditor(cfg *config.Config) (*config.Config, error) {
 			Options(
 				huh.NewOption(fmt.Sprintf("Provider Settings   (%s)", providerSummary), "provider"),
 				huh.NewOption(fmt.Sprintf("Generation Settings  (%s)", genSummary), "generation"),
+				huh.NewOption(fmt.Sprintf("Commit Settings     (%s)", commitSummary(cfg)), "commit"),
 				huh.NewOption(fmt.Sprintf("Update Settings     (%s)", updateSummary), "update"),
+				huh.NewOption(fmt.Sprintf("Profiles            (%s)", profileSummary(cfg)), "profiles"),
 				huh.NewOption("Save & Exit", "save"),
 			).
 			Value(&choice)
@@ -68,10 +72,18 @@ func RunConfigEditor(cfg *config.Config) (*config.Config, error) {
 			if err := editGenerationSettings(cfg); err != nil {
 				return cfg, err
 			}
+		case "commit":
+			if err := editCommitSettings(cfg); err != nil {
+				return cfg, err
+			}
 		case "update":
 			if err := editUpdateSettings(cfg); err != nil {
 				return cfg, err
 			}
+		case "profiles":
+			if err := editProfiles(cfg); err != nil {
+				return cfg, err
+			}
 		case "save":
 			cfg.ConfigVersion = config.CurrentConfigVersion
 			if err := config.Save(cfg); err != nil {
@@ -85,17 +97,20 @@ func RunConfigEditor(cfg *config.Config) (*config.Config, error) {
 	}
 }
 
+// newProviderOption is the sentinel select value for adding a named provider.
+const newProviderOption = "__new__"
+
 // editProviderSettings runs the provider selection and details forms.
 // It modifies cfg in-place. Used by both RunConfigEditor and RunWizard.
 func editProviderSettings(cfg *config.Config) error {
 	providerName := cfg.DefaultProvider
-	names := llm.ProviderNames()
-	displayNames := llm.ProviderDisplayNames()
+	names := llm.ConfiguredProviderNames(cfg)
 
-	options := make([]huh.Option[string], len(names))
-	for i, name := range names {
-		options[i] = huh.NewOption(displayNames[name], name)
+	options := make([]huh.Option[string], 0, len(names)+1)
+	for _, name := range names {
+		options = append(options, huh.NewOption(llm.ProviderDisplayName(name, cfg.Providers[name]), name))
 	}
+	options = append(options, huh.NewOption("Add a named provider...", newProviderOption))
 
 	providerSelect := huh.NewSelect[string]().
 		Title("Select your LLM provider").
@@ -106,71 +121,308 @@ func editProviderSettings(cfg *config.Config) error {
 		return err
 	}
 
+	if cfg.Providers == nil {
+		cfg.Providers = make(map[string]config.ProviderConfig)
+	}
+	if providerName == newProviderOption {
+		name, err := addNamedProvider(cfg)
+		if err != nil {
+			return err
+		}
+		providerName = name
+	}
+
+	existing := cfg.Providers[providerName]
+	providerType := llm.ProviderType(providerName, existing)
+	switch providerType {
+	case llm.TypeExec:
+		return editExecProvider(cfg, providerName)
+	case llm.TypeHeuristic:
+		cfg.DefaultProvider = providerName
+		cfg.Providers[providerName] = config.ProviderConfig{}
+		return nil
+	}
+
 	// Pre-fill from existing provider config
-	var apiKey, model, baseURL string
-	if p, ok := cfg.Providers[providerName]; ok {
-		apiKey = p.APIKey
-		model = p.Model
-		baseURL = p.BaseURL
+	apiKey := existing.APIKey
+	apiKeyEnv := existing.APIKeyEnv
+	apiKeyCmd := existing.APIKeyCmd
+	model := existing.Model
+	baseURL := existing.BaseURL
+	keySource := config.KeySourceConfig
+	if src := existing.APIKeySource(); src != config.KeySourceNone {
+		keySource = src
 	}
 
-	apiKeyInput := huh.NewInput().
-		Title(fmt.Sprintf("Enter your %s API key", displayNames[providerName])).
-		Value(&apiKey).
-		EchoMode(huh.EchoModePassword).
+	var fields []huh.Field
+	if llm.RequiresAPIKey(providerType) {
+		sourceSelect := huh.NewSelect[string]().
+			Title("How should fire-commit read the API key?").
+			Options(
+				huh.NewOption("Store it in the config file", config.KeySourceConfig),
+				huh.NewOption("Read it from an environment variable", config.KeySourceEnv),
+				huh.NewOption("Run a credential command (e.g. pass, op, security)", config.KeySourceCommand),
+			).
+			Value(&keySource)
+
+		if err := huh.NewForm(huh.NewGroup(sourceSelect)).Run(); err != nil {
+			return err
+		}
+		fields = append(fields, apiKeyInput(providerName, existing, keySource, &apiKey, &apiKeyEnv, &apiKeyCmd))
+	} else {
+		keySource = config.KeySourceNone
+	}
+
+	if input := baseURLInput(providerName, providerType, &baseURL); input != nil {
+		fields = append(fields, input)
+	}
+
+	if len(fields) > 0 {
+		if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+			return err
+		}
+	}
+
+	// Only keep the field for the chosen key source so the resolution order
+	// in ProviderConfig.ResolveAPIKey is unambiguous.
+	provCfg := existing
+	provCfg.APIKey, provCfg.APIKeyEnv, provCfg.APIKeyCmd = "", "", ""
+	switch keySource {
+	case config.KeySourceEnv:
+		provCfg.APIKeyEnv = strings.TrimSpace(apiKeyEnv)
+	case config.KeySourceCommand:
+		provCfg.APIKeyCmd = strings.TrimSpace(apiKeyCmd)
+	case config.KeySourceConfig:
+		provCfg.APIKey = apiKey
+	}
+	provCfg.BaseURL = strings.TrimSpace(baseURL)
+
+	defaultModel := llm.DefaultModel(providerName)
+	if defaultModel == "" {
+		defaultModel = llm.DefaultModel(providerType)
+	}
+	provCfg.Model = model
+	if err := selectModel(providerName, provCfg, &model); err != nil {
+		return err
+	}
+
+	if model == "" {
+		model = defaultModel
+	}
+	provCfg.Model = model
+
+	cfg.DefaultProvider = providerName
+	cfg.Providers[providerName] = provCfg
+	return nil
+}
+
+// addNamedProvider asks for the name and type of an additional provider entry
+// and records it in cfg. Details are filled in by editProviderSettings.
+func addNamedProvider(cfg *config.Config) (string, error) {
+	var name string
+	providerType := llm.TypeOpenAICompat
+
+	typeNames := llm.ProviderTypeDisplayNames()
+	typeOptions := make([]huh.Option[string], 0, len(llm.ProviderTypes()))
+	for _, t := range llm.ProviderTypes() {
+		typeOptions = append(typeOptions, huh.NewOption(typeNames[t], t))
+	}
+
+	nameInput := huh.NewInput().
+		Title("Name for this provider").
+		Placeholder("openrouter").
+		Value(&name).
 		Validate(func(s string) error {
-			if s == "" {
-				return fmt.Errorf("API key is required")
+			s = strings.TrimSpace(s)
+			switch {
+			case s == "":
+				return fmt.Errorf("name is required")
+			case strings.ContainsAny(s, " \t:"):
+				return fmt.Errorf("name must not contain spaces or colons")
+			case llm.IsBuiltinProvider(s):
+				return fmt.Errorf("%q is a built-in provider", s)
+			}
+			if _, ok := cfg.Providers[s]; ok {
+				return fmt.Errorf("provider %q already exists", s)
 			}
 			return nil
 		})
+	typeSelect := huh.NewSelect[string]().
+		Title("Provider type").
+		Options(typeOptions...).
+		Value(&providerType)
+
+	if err := huh.NewForm(huh.NewGroup(nameInput, typeSelect)).Run(); err != nil {
+		return "", err
+	}
+
+	name = strings.TrimSpace(name)
+	cfg.Providers[name] = config.ProviderConfig{Type: providerType}
+	return name, nil
+}
+
+// apiKeyInput returns the input for the chosen key source.
+func apiKeyInput(providerName string, provCfg config.ProviderConfig, keySource string, apiKey, apiKeyEnv, apiKeyCmd *string) *huh.Input {
+	switch keySource {
+	case config.KeySourceEnv:
+		return huh.NewInput().
+			Title("Environment variable holding the API key").
+			Placeholder(strings.ToUpper(strings.ReplaceAll(providerName, "-", "_")) + "_API_KEY").
+			Value(apiKeyEnv).
+			Validate(func(s string) error {
+				if strings.TrimSpace(s) == "" {
+					return fmt.Errorf("variable name is required")
+				}
+				return nil
+			})
+	case config.KeySourceCommand:
+		return huh.NewInput().
+			Title("Command that prints the API key").
+			Placeholder("pass show " + providerName).
+			Value(apiKeyCmd).
+			Validate(func(s string) error {
+				if strings.TrimSpace(s) == "" {
+					return fmt.Errorf("command is required")
+				}
+				return nil
+			})
+	default:
+		return huh.NewInput().
+			Title(fmt.Sprintf("Enter your %s API key", llm.ProviderDisplayName(providerName, provCfg))).
+			Value(apiKey).
+			EchoMode(huh.EchoModePassword).
+			Validate(func(s string) error {
+				if s == "" {
+					return fmt.Errorf("API key is required")
+				}
+				return nil
+			})
+	}
+}
 
-	fields := []huh.Field{apiKeyInput}
+// baseURLInput returns the base URL input for providers that need or allow
+// one, or nil for built-in providers with a fixed endpoint.
+func baseURLInput(providerName, providerType string, baseURL *string) *huh.Input {
+	if llm.IsBuiltinProvider(providerName) && providerName != "custom" {
+		*baseURL = ""
+		return nil
+	}
 
-	if providerName == "custom" {
-		baseURLInput := huh.NewInput().
+	input := huh.NewInput().Value(baseURL)
+	switch providerType {
+	case llm.TypeOpenAICompat:
+		return input.
 			Title("Enter the OpenAI-compatible API base URL").
 			Placeholder("https://api.example.com/v1").
-			Value(&baseURL).
 			Validate(func(s string) error {
-				if s == "" {
-					return fmt.Errorf("base URL is required for custom provider")
+				if strings.TrimSpace(s) == "" {
+					return fmt.Errorf("base URL is required for this provider")
 				}
 				return nil
 			})
-		fields = append(fields, baseURLInput)
+	case llm.TypeOllama:
+		return input.
+			Title("Ollama API base URL (empty for local default)").
+			Placeholder("http://localhost:11434/v1")
+	default:
+		return input.
+			Title("API base URL (empty for the official endpoint)")
 	}
+}
 
-	defaultModel := llm.DefaultModel(providerName)
+// editExecProvider asks for the command run by the exec provider. The model
+// is optional and only passed through to the command.
+func editExecProvider(cfg *config.Config, providerName string) error {
+	provCfg := cfg.Providers[providerName]
+	command := provCfg.Command
+	model := provCfg.Model
+
+	commandInput := huh.NewInput().
+		Title("Command to run (reads a JSON request on stdin, prints JSON lines)").
+		Placeholder("~/bin/commit-gateway").
+		Value(&command).
+		Validate(func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return fmt.Errorf("command is required")
+			}
+			return nil
+		})
 	modelInput := huh.NewInput().
-		Title("Model name").
-		Placeholder(defaultModel).
+		Title("Model name passed to the command (optional)").
 		Value(&model)
-	fields = append(fields, modelInput)
 
-	if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+	if err := huh.NewForm(huh.NewGroup(commandInput, modelInput)).Run(); err != nil {
 		return err
 	}
 
-	if model == "" {
-		model = defaultModel
-	}
+	provCfg.Command = strings.TrimSpace(command)
+	provCfg.Model = strings.TrimSpace(model)
+	cfg.DefaultProvider = providerName
+	cfg.Providers[providerName] = provCfg
+	return nil
+}
+
+// manualModelOption is the sentinel select value for typing a model name by hand.
+const manualModelOption = "__manual__"
 
-	// Apply to cfg
-	provCfg := config.ProviderConfig{
-		APIKey: apiKey,
-		Model:  model,
+// selectModel asks the provider for its model list and offers a picker.
+// If the list cannot be fetched it falls back to a free-text input.
+func selectModel(providerName string, provCfg config.ProviderConfig, model *string) error {
+	defaultModel := llm.DefaultModel(providerName)
+
+	fmt.Println(subtitleStyle.Render("   Fetching available models..."))
+	models, err := fetchModels(providerName, provCfg)
+	if err != nil {
+		fmt.Println(subtitleStyle.Render(fmt.Sprintf("   Could not list models (%v); enter the model name manually.", err)))
 	}
-	if providerName == "custom" {
-		provCfg.BaseURL = baseURL
+
+	if len(models) > 0 {
+		selected := *model
+		if selected == "" {
+			selected = defaultModel
+		}
+		options := make([]huh.Option[string], 0, len(models)+1)
+		for _, m := range models {
+			label := m
+			if m == defaultModel {
+				label += " (default)"
+			}
+			options = append(options, huh.NewOption(label, m))
+		}
+		options = append(options, huh.NewOption("Other (enter manually)", manualModelOption))
+
+		modelSelect := huh.NewSelect[string]().
+			Title("Model").
+			Description("Type / to filter.").
+			Options(options...).
+			Height(12).
+			Value(&selected)
+
+		if err := huh.NewForm(huh.NewGroup(modelSelect)).Run(); err != nil {
+			return err
+		}
+		if selected != manualModelOption {
+			*model = selected
+			return nil
+		}
 	}
 
-	cfg.DefaultProvider = providerName
-	if cfg.Providers == nil {
-		cfg.Providers = make(map[string]config.ProviderConfig)
+	modelInput := huh.NewInput().
+		Title("Model name").
+		Placeholder(defaultModel).
+		Value(model)
+
+	return huh.NewForm(huh.NewGroup(modelInput)).Run()
+}
+
+func fetchModels(providerName string, provCfg config.ProviderConfig) ([]string, error) {
+	provider, err := llm.NewListingProvider(providerName, provCfg)
+	if err != nil {
+		return nil, err
 	}
-	cfg.Providers[providerName] = provCfg
-	return nil
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	return llm.ListModels(ctx, provider)
 }
 
 // editGenerationSettings runs the generation settings form.
@@ -178,17 +430,24 @@ func editGenerationSettings(cfg *config.Config) error {
 	language := cfg.Generation.Language
 	numSuggestions := cfg.Generation.NumSuggestions
 	maxDiffStr := strconv.Itoa(cfg.Generation.MaxDiffLines)
+	offlineFallback := cfg.Generation.OfflineFallback
+	structuredOutput := cfg.Generation.StructuredOutput
+	convention := cfg.Generation.Convention
+	maxConcurrency := cfg.Generation.MaxConcurrency
+	requestTimeoutStr := formatTimeout(cfg.Generation.RequestTimeout)
+	firstTokenTimeoutStr := formatTimeout(cfg.Generation.FirstTokenTimeout)
 
 	languageSelect := huh.NewSelect[string]().
 		Title("Commit message language").
-		Options(
-			huh.NewOption("English", "en"),
-			huh.NewOption("中文", "zh"),
-			huh.NewOption("日本語", "ja"),
-			huh.NewOption("한국어", "ko"),
-		).
+		Options(languageOptions(language)...).
+		Height(10).
 		Value(&language)
 
+	conventionSelect := huh.NewSelect[string]().
+		Title("Commit message convention").
+		Options(conventionOptions()...).
+		Value(&convention)
+
 	numSugSelect := huh.NewSelect[int]().
 		Title("Number of suggestions").
 		Options(
@@ -212,10 +471,48 @@ func editGenerationSettings(cfg *config.Config) error {
 			return nil
 		})
 
-	if err := huh.NewForm(huh.NewGroup(languageSelect, numSugSelect, maxDiffInput)).Run(); err != nil {
+	fallbackConfirm := huh.NewConfirm().
+		Title("Fall back to offline heuristics when the provider is unreachable").
+		Value(&offlineFallback)
+
+	structuredConfirm := huh.NewConfirm().
+		Title("Request structured suggestions (body, footers and rationale)").
+		Value(&structuredOutput)
+
+	concurrencySelect := huh.NewSelect[int]().
+		Title("Concurrent requests").
+		Options(
+			huh.NewOption("All at once (default)", 0),
+			huh.NewOption("1", 1),
+			huh.NewOption("2", 2),
+			huh.NewOption("3", 3),
+		).
+		Value(&maxConcurrency)
+
+	requestTimeoutInput := huh.NewInput().
+		Title("Request timeout (e.g. 2m, 0 to disable)").
+		Value(&requestTimeoutStr).
+		Validate(validateTimeout)
+
+	firstTokenTimeoutInput := huh.NewInput().
+		Title("First-token timeout (e.g. 30s, 0 to disable)").
+		Value(&firstTokenTimeoutStr).
+		Validate(validateTimeout)
+
+	if err := huh.NewForm(
+		huh.NewGroup(languageSelect, conventionSelect, numSugSelect, maxDiffInput, fallbackConfirm, structuredConfirm),
+		huh.NewGroup(concurrencySelect, requestTimeoutInput, firstTokenTimeoutInput),
+	).Run(); err != nil {
 		return err
 	}
 
+	cfg.Generation.Convention = convention
+	cfg.Generation.MaxConcurrency = maxConcurrency
+	cfg.Generation.RequestTimeout, _ = parseTimeout(requestTimeoutStr)
+	cfg.Generation.FirstTokenTimeout, _ = parseTimeout(firstTokenTimeoutStr)
+
+	cfg.Generation.StructuredOutput = structuredOutput
+	cfg.Generation.OfflineFallback = offlineFallback
 	cfg.Generation.Language = language
 	cfg.Generation.NumSuggestions = numSuggestions
 	if n, err := strconv.Atoi(maxDiffStr); err == nil {
@@ -224,6 +521,53 @@ func editGenerationSettings(cfg *config.Config) error {
 	return nil
 }
 
+// languageOptions lists auto-detection and the known languages. A current
+// value without a table entry (any BCP-47 tag is accepted) is kept as an option.
+func languageOptions(current string) []huh.Option[string] {
+	options := []huh.Option[string]{huh.NewOption("Auto-detect from recent commits", llm.LanguageAuto)}
+	known := current == "" || current == llm.LanguageAuto
+	for _, l := range llm.Languages() {
+		options = append(options, huh.NewOption(l.Native, l.Tag))
+		known = known || l.Tag == current
+	}
+	if !known {
+		options = append(options, huh.NewOption(current, current))
+	}
+	return options
+}
+
+func conventionOptions() []huh.Option[string] {
+	return []huh.Option[string]{
+		huh.NewOption("Conventional Commits (feat(scope): ...)", ""),
+		huh.NewOption("Plain imperative subject", llm.ConventionPlain),
+	}
+}
+
+func formatTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "0"
+	}
+	return d.String()
+}
+
+// parseTimeout accepts Go durations and "0" for no timeout.
+func parseTimeout(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("must be a duration like 30s or 2m")
+	}
+	return d, nil
+}
+
+func validateTimeout(s string) error {
+	_, err := parseTimeout(s)
+	return err
+}
+
 // editUpdateSettings runs the update settings form.
 func editUpdateSettings(cfg *config.Config) error {
 	updateChannel := cfg.UpdateChannel
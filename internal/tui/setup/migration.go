@@ -60,6 +60,22 @@ func applyDefaults(cfg *config.Config, fromVersion int) {
 		// v1 -> v2: UpdateCache introduced, default false (check every run)
 		cfg.UpdateCache = false
 	}
+	if fromVersion < 3 {
+		// v2 -> v3: push.sync written out with its default unless set by hand
+		if cfg.Push.SyncMode == "" {
+			cfg.Push.SyncMode = config.SyncRebase
+		}
+	}
+	// v3 -> v4: request_timeout and first_token_timeout. Load fills in
+	// missing values from DefaultConfig, so there is nothing to set.
+	if fromVersion < 5 {
+		// v4 -> v5: staging_policy written out with its default unless set
+		// by hand
+		if cfg.Commit.StagingPolicy == "" {
+			cfg.Commit.StagingPolicy = config.StagingAsk
+		}
+	}
+	// v5 -> v6: offline_fallback is only asked about; its value is kept.
 }
 
 // runMigrationWizard presents huh forms for each new field added since fromVersion.
@@ -103,5 +119,68 @@ func runMigrationWizard(cfg *config.Config, fromVersion int) error {
 		cfg.UpdateCache = updateCache
 	}
 
+	if fromVersion < 3 {
+		// v2 -> v3: sync before push
+		syncMode := cfg.Push.Sync()
+		syncSelect := huh.NewSelect[string]().
+			Title("When the upstream has new commits before a push").
+			Options(syncOptions()...).
+			Value(&syncMode)
+
+		if err := huh.NewForm(huh.NewGroup(syncSelect)).Run(); err != nil {
+			return err
+		}
+		cfg.Push.SyncMode = syncMode
+	}
+
+	if fromVersion < 4 {
+		// v3 -> v4: request timeouts
+		requestTimeoutStr := formatTimeout(cfg.Generation.RequestTimeout)
+		firstTokenTimeoutStr := formatTimeout(cfg.Generation.FirstTokenTimeout)
+		requestTimeoutInput := huh.NewInput().
+			Title("Request timeout (e.g. 2m, 0 to disable)").
+			Value(&requestTimeoutStr).
+			Validate(validateTimeout)
+		firstTokenTimeoutInput := huh.NewInput().
+			Title("First-token timeout (e.g. 30s, 0 to disable)").
+			Value(&firstTokenTimeoutStr).
+			Validate(validateTimeout)
+
+		if err := huh.NewForm(huh.NewGroup(requestTimeoutInput, firstTokenTimeoutInput)).Run(); err != nil {
+			return err
+		}
+		cfg.Generation.RequestTimeout, _ = parseTimeout(requestTimeoutStr)
+		cfg.Generation.FirstTokenTimeout, _ = parseTimeout(firstTokenTimeoutStr)
+	}
+
+	if fromVersion < 5 {
+		// v4 -> v5: staging policy
+		staging := cfg.Commit.Staging()
+		stagingSelect := huh.NewSelect[string]().
+			Title("When changes are not staged").
+			Description("Previously everything was staged with git add -A.").
+			Options(stagingOptions()...).
+			Value(&staging)
+
+		if err := huh.NewForm(huh.NewGroup(stagingSelect)).Run(); err != nil {
+			return err
+		}
+		cfg.Commit.StagingPolicy = staging
+	}
+
+	if fromVersion < 6 {
+		// v5 -> v6: offline fallback
+		offlineFallback := cfg.Generation.OfflineFallback
+		fallbackConfirm := huh.NewConfirm().
+			Title("Fall back to offline heuristics when the provider is unreachable?").
+			Description("Heuristic suggestions are marked as such; No shows the connection error instead.").
+			Value(&offlineFallback)
+
+		if err := huh.NewForm(huh.NewGroup(fallbackConfirm)).Run(); err != nil {
+			return err
+		}
+		cfg.Generation.OfflineFallback = offlineFallback
+	}
+
 	return nil
 }
@@ -0,0 +1,206 @@
+package setup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/llm"
+)
+
+// Sentinel select values in the profile menus.
+const (
+	newProfileOption  = "__new__"
+	backProfileOption = "__back__"
+)
+
+// profileSummary describes the configured profiles for the main menu.
+func profileSummary(cfg *config.Config) string {
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// editProfiles lists profiles and lets the user add, edit or delete them.
+// It modifies cfg in-place.
+func editProfiles(cfg *config.Config) error {
+	for {
+		choice := backProfileOption
+		options := make([]huh.Option[string], 0, len(cfg.Profiles)+2)
+		for _, name := range cfg.ProfileNames() {
+			options = append(options, huh.NewOption(name, name))
+		}
+		options = append(options,
+			huh.NewOption("Add a profile...", newProfileOption),
+			huh.NewOption("Back", backProfileOption),
+		)
+
+		menu := huh.NewSelect[string]().
+			Title("Profiles").
+			Description("Selected with --profile, FIRECOMMIT_PROFILE, or by repository path/remote.").
+			Options(options...).
+			Value(&choice)
+		if err := huh.NewForm(huh.NewGroup(menu)).Run(); err != nil {
+			return err
+		}
+
+		switch choice {
+		case backProfileOption:
+			return nil
+		case newProfileOption:
+			if err := editProfile(cfg, ""); err != nil {
+				return err
+			}
+		default:
+			if err := editProfile(cfg, choice); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// editProfile runs the form for one profile. An empty name creates a new one.
+func editProfile(cfg *config.Config, name string) error {
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+	p := cfg.Profiles[name]
+	isNew := name == ""
+
+	if !isNew {
+		action := "edit"
+		actionSelect := huh.NewSelect[string]().
+			Title(fmt.Sprintf("Profile %q", name)).
+			Options(
+				huh.NewOption("Edit", "edit"),
+				huh.NewOption("Delete", "delete"),
+				huh.NewOption("Back", "back"),
+			).
+			Value(&action)
+		if err := huh.NewForm(huh.NewGroup(actionSelect)).Run(); err != nil {
+			return err
+		}
+		switch action {
+		case "delete":
+			delete(cfg.Profiles, name)
+			return nil
+		case "back":
+			return nil
+		}
+	}
+
+	profileName := name
+	provider := p.Provider
+	model := p.Model
+	language := p.Language
+	numSuggestions := strconv.Itoa(p.NumSuggestions)
+	if p.NumSuggestions == 0 {
+		numSuggestions = ""
+	}
+	convention := p.Convention
+	paths := strings.Join(p.Paths, ", ")
+	remotes := strings.Join(p.Remotes, ", ")
+
+	providerOptions := []huh.Option[string]{huh.NewOption("Keep default provider", "")}
+	for _, n := range llm.ConfiguredProviderNames(cfg) {
+		if _, ok := cfg.Providers[n]; ok {
+			providerOptions = append(providerOptions, huh.NewOption(llm.ProviderDisplayName(n, cfg.Providers[n]), n))
+		}
+	}
+	languageOpts := append([]huh.Option[string]{huh.NewOption("Keep default language", "")}, languageOptions(language)...)
+	conventionOpts := []huh.Option[string]{
+		huh.NewOption("Keep default convention", ""),
+		huh.NewOption("Conventional Commits", llm.ConventionConventional),
+		huh.NewOption("Plain imperative subject", llm.ConventionPlain),
+	}
+
+	var fields []huh.Field
+	if isNew {
+		fields = append(fields, huh.NewInput().
+			Title("Profile name").
+			Placeholder("work").
+			Value(&profileName).
+			Validate(func(s string) error {
+				s = strings.TrimSpace(s)
+				if s == "" {
+					return fmt.Errorf("name is required")
+				}
+				if _, ok := cfg.Profiles[s]; ok {
+					return fmt.Errorf("profile %q already exists", s)
+				}
+				return nil
+			}))
+	}
+	fields = append(fields,
+		huh.NewSelect[string]().
+			Title("Provider").
+			Options(providerOptions...).
+			Value(&provider),
+		huh.NewInput().
+			Title("Model (empty keeps the provider's model)").
+			Value(&model),
+		huh.NewSelect[string]().
+			Title("Commit message language").
+			Options(languageOpts...).
+			Height(10).
+			Value(&language),
+		huh.NewInput().
+			Title("Number of suggestions (empty keeps default)").
+			Value(&numSuggestions).
+			Validate(func(s string) error {
+				if s = strings.TrimSpace(s); s == "" {
+					return nil
+				}
+				if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+					return fmt.Errorf("must be a positive integer")
+				}
+				return nil
+			}),
+		huh.NewSelect[string]().
+			Title("Commit message convention").
+			Options(conventionOpts...).
+			Value(&convention),
+	)
+
+	matchFields := []huh.Field{
+		huh.NewInput().
+			Title("Use automatically for repositories under (comma-separated globs)").
+			Placeholder("~/work/*").
+			Value(&paths),
+		huh.NewInput().
+			Title("Use automatically for remote URLs matching (comma-separated globs)").
+			Placeholder("*github.com:acme/*").
+			Value(&remotes),
+	}
+
+	if err := huh.NewForm(huh.NewGroup(fields...), huh.NewGroup(matchFields...)).Run(); err != nil {
+		return err
+	}
+
+	n, _ := strconv.Atoi(strings.TrimSpace(numSuggestions))
+	cfg.Profiles[strings.TrimSpace(profileName)] = config.Profile{
+		Provider:       provider,
+		Model:          strings.TrimSpace(model),
+		Language:       language,
+		NumSuggestions: n,
+		Convention:     convention,
+		Paths:          splitList(paths),
+		Remotes:        splitList(remotes),
+	}
+	return nil
+}
+
+// splitList splits a comma-separated input into trimmed, non-empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
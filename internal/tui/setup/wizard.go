@@ -36,12 +36,11 @@ func RunWizard() (*config.Config, error) {
 		return nil, fmt.Errorf("failed to save config: %w", err)
 	}
 
-	displayNames := llm.ProviderDisplayNames()
 	p := cfg.Providers[cfg.DefaultProvider]
 
 	fmt.Println()
 	fmt.Println(titleStyle.Render("✓ Configuration saved!"))
-	fmt.Println(subtitleStyle.Render(fmt.Sprintf("  Provider: %s | Model: %s", displayNames[cfg.DefaultProvider], p.Model)))
+	fmt.Println(subtitleStyle.Render(fmt.Sprintf("  Provider: %s | Model: %s", llm.ProviderDisplayName(cfg.DefaultProvider, p), p.Model)))
 	fmt.Println()
 
 	return cfg, nil
@@ -0,0 +1,459 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+	"github.com/lieyanc/fire-commit/internal/llm"
+)
+
+// SplitUnit is one staged hunk (or whole file) that can be assigned to a
+// commit by "firecommit split".
+type SplitUnit struct {
+	Path string
+	// Label describes the unit, e.g. the hunk header.
+	Label string
+	// Patch is the file header followed by the hunk.
+	Patch string
+}
+
+// minSplitUnitLines is the least number of patch lines sent per unit when
+// the listing is shortened to generation.max_diff_lines.
+const minSplitUnitLines = 20
+
+// splitPlanMsg delivers the model's grouping.
+type splitPlanMsg struct {
+	generation int
+	groups     []llm.SplitGroup
+	err        error
+}
+
+// splitRow is one line of the grouping: a commit header when unit is -1,
+// otherwise a unit of that commit.
+type splitRow struct {
+	group int
+	unit  int
+}
+
+// SplitModel is the grouping editor of "firecommit split".
+type SplitModel struct {
+	cfg     *config.Config
+	opts    llm.GenerateOptions
+	units   []SplitUnit
+	listing string
+
+	groups []llm.SplitGroup
+	// generation identifies the active planning request.
+	generation int
+	planning   bool
+	planErr    error
+	notice     string
+
+	cursor     int
+	spinner    spinner.Model
+	editArea   textarea.Model
+	editing    bool
+	confirming bool
+	applied    bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	width  int
+	height int
+}
+
+// NewSplitModel creates the grouping editor for units in diff order.
+func NewSplitModel(cfg *config.Config, repo *git.Repo, units []SplitUnit) SplitModel {
+	ta := textarea.New()
+	ta.Placeholder = "Commit message..."
+	ta.CharLimit = 2000
+	ta.SetWidth(60)
+	ta.SetHeight(5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return SplitModel{
+		cfg:        cfg,
+		opts:       GenerateOptions(cfg, repo),
+		units:      units,
+		listing:    splitListing(units, cfg.Generation.MaxDiffLines),
+		generation: 1,
+		planning:   true,
+		spinner:    newSpinner(),
+		editArea:   ta,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// splitListing numbers the units for the model. When maxLines is set, each
+// unit's patch is cut to an equal share of it.
+func splitListing(units []SplitUnit, maxLines int) string {
+	perUnit := 0
+	if maxLines > 0 && len(units) > 0 {
+		perUnit = max(maxLines/len(units), minSplitUnitLines)
+	}
+	hunks := make([]llm.SplitHunk, len(units))
+	for i, u := range units {
+		patch := u.Patch
+		if lines := strings.Split(patch, "\n"); perUnit > 0 && len(lines) > perUnit {
+			patch = strings.Join(lines[:perUnit], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-perUnit)
+		}
+		hunks[i] = llm.SplitHunk{Path: u.Path, Patch: patch}
+	}
+	return llm.FormatSplitHunks(hunks)
+}
+
+func (m SplitModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.plan())
+}
+
+// plan asks the provider to group the units.
+func (m SplitModel) plan() tea.Cmd {
+	cfg, opts, ctx := m.cfg, m.opts, m.ctx
+	listing, n, generation := m.listing, len(m.units), m.generation
+	return func() tea.Msg {
+		provider, err := llm.NewProvider(cfg)
+		if err != nil {
+			return splitPlanMsg{generation: generation, err: err}
+		}
+		groups, err := llm.GenerateSplit(ctx, provider, listing, n, opts)
+		return splitPlanMsg{generation: generation, groups: groups, err: err}
+	}
+}
+
+func (m SplitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.editArea.SetWidth(max(contentWidthFor(m.width)-4, minInputWidth))
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case splitPlanMsg:
+		if msg.generation != m.generation {
+			return m, nil
+		}
+		m.planning = false
+		m.planErr = msg.err
+		if msg.err == nil {
+			m.setGroups(msg.groups)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.CtrlC) {
+			m.cancel()
+			return m, tea.Quit
+		}
+		switch {
+		case m.editing:
+			return m.updateSplitEdit(msg)
+		case m.confirming:
+			return m.updateSplitConfirm(msg)
+		}
+		return m.updateSplitGroups(msg)
+	}
+
+	if m.editing {
+		var cmd tea.Cmd
+		m.editArea, cmd = m.editArea.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// setGroups takes the model's plan. Units it left out are collected in a
+// final commit without a message.
+func (m *SplitModel) setGroups(groups []llm.SplitGroup) {
+	assigned := make([]bool, len(m.units))
+	for _, g := range groups {
+		for _, u := range g.Hunks {
+			assigned[u] = true
+		}
+	}
+	var rest []int
+	for i, ok := range assigned {
+		if !ok {
+			rest = append(rest, i)
+		}
+	}
+	if len(rest) > 0 {
+		groups = append(groups, llm.SplitGroup{Hunks: rest})
+	}
+	m.groups = groups
+	m.cursor = 0
+}
+
+// rows flattens the groups into the lines shown.
+func (m SplitModel) rows() []splitRow {
+	var rows []splitRow
+	for g, group := range m.groups {
+		rows = append(rows, splitRow{group: g, unit: -1})
+		for _, u := range group.Hunks {
+			rows = append(rows, splitRow{group: g, unit: u})
+		}
+	}
+	return rows
+}
+
+// rowIndex returns the row showing unit (or the header of group when unit
+// is -1).
+func (m SplitModel) rowIndex(group, unit int) int {
+	for i, r := range m.rows() {
+		if unit >= 0 && r.unit == unit || unit < 0 && r.unit < 0 && r.group == group {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m SplitModel) updateSplitGroups(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, keys.Quit) {
+		m.cancel()
+		return m, tea.Quit
+	}
+	if m.planning {
+		return m, nil
+	}
+	if key.Matches(msg, keys.Regen) {
+		m.generation++
+		m.planning = true
+		m.planErr = nil
+		m.notice = ""
+		m.groups = nil
+		return m, tea.Batch(m.spinner.Tick, m.plan())
+	}
+	if len(m.groups) == 0 {
+		return m, nil
+	}
+
+	m.notice = ""
+	rows := m.rows()
+	row := rows[m.cursor]
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.cursor < len(rows)-1 {
+			m.cursor++
+		}
+	case key.Matches(msg, keys.MovePrev):
+		if row.unit >= 0 && row.group > 0 {
+			m.moveUnit(row.unit, row.group, row.group-1)
+		}
+	case key.Matches(msg, keys.MoveNext):
+		if row.unit >= 0 {
+			m.moveUnit(row.unit, row.group, row.group+1)
+		}
+	case key.Matches(msg, keys.NewGroup):
+		if row.unit >= 0 {
+			m.moveUnit(row.unit, row.group, len(m.groups))
+		}
+	case key.Matches(msg, keys.GroupUp):
+		if row.group > 0 {
+			m.swapGroups(row.group, row.group-1)
+		}
+	case key.Matches(msg, keys.GroupDown):
+		if row.group < len(m.groups)-1 {
+			m.swapGroups(row.group, row.group+1)
+		}
+	case key.Matches(msg, keys.Edit):
+		m.editArea.SetValue(m.groups[row.group].Message)
+		m.editing = true
+		return m, m.editArea.Focus()
+	case key.Matches(msg, keys.Enter):
+		for i, g := range m.groups {
+			if g.Message == "" {
+				m.notice = fmt.Sprintf("commit %d has no message — press e to write one", i+1)
+				m.cursor = m.rowIndex(i, -1)
+				return m, nil
+			}
+		}
+		m.confirming = true
+	}
+	return m, nil
+}
+
+// moveUnit moves unit from group from to group to, creating a new last
+// group when to is past the end and dropping from when it becomes empty.
+func (m *SplitModel) moveUnit(unit, from, to int) {
+	if to == len(m.groups) {
+		if len(m.groups[from].Hunks) == 1 {
+			// Already alone in its commit.
+			return
+		}
+		m.groups = append(m.groups, llm.SplitGroup{})
+	}
+	src := &m.groups[from]
+	src.Hunks = slices.DeleteFunc(slices.Clone(src.Hunks), func(u int) bool { return u == unit })
+	dst := &m.groups[to]
+	dst.Hunks = append(slices.Clone(dst.Hunks), unit)
+	slices.Sort(dst.Hunks)
+	if len(src.Hunks) == 0 {
+		m.groups = slices.Delete(m.groups, from, from+1)
+	}
+	m.cursor = m.rowIndex(0, unit)
+}
+
+func (m *SplitModel) swapGroups(a, b int) {
+	m.groups[a], m.groups[b] = m.groups[b], m.groups[a]
+	m.cursor = m.rowIndex(b, -1)
+}
+
+func (m SplitModel) updateSplitEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Escape):
+		m.editing = false
+		m.editArea.Blur()
+		return m, nil
+	case key.Matches(msg, keys.Save):
+		if value := strings.TrimSpace(m.editArea.Value()); value != "" {
+			m.groups[m.rows()[m.cursor].group].Message = value
+		}
+		m.editing = false
+		m.editArea.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.editArea, cmd = m.editArea.Update(msg)
+	return m, cmd
+}
+
+func (m SplitModel) updateSplitConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		m.applied = true
+		m.cancel()
+		return m, tea.Quit
+	case "n", "esc":
+		m.confirming = false
+	case "q":
+		m.cancel()
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// splitListHeight is the number of rows that fit on screen.
+func (m SplitModel) splitListHeight() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-12, 5)
+}
+
+func (m SplitModel) View() string {
+	var b strings.Builder
+	contentWidth := contentWidthFor(m.width)
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("🔥 fire-commit split — %d hunks", len(m.units))))
+	b.WriteString("\n\n")
+
+	switch {
+	case m.editing:
+		b.WriteString(fmt.Sprintf("Message for commit %d:\n\n", m.rows()[m.cursor].group+1))
+		b.WriteString(m.editArea.View())
+		b.WriteString(helpStyle.Render("\n\n  ctrl+s save • esc cancel"))
+		return renderBoxWidth(b.String(), m.width)
+	case m.planning:
+		b.WriteString(m.spinner.View() + " Grouping staged changes into commits...")
+		b.WriteString(helpStyle.Render("\n\n  q quit"))
+		return renderBoxWidth(b.String(), m.width)
+	case m.planErr != nil:
+		b.WriteString(errorStyle.Render(wrapText("✗ "+m.planErr.Error(), contentWidth)))
+		b.WriteString(helpStyle.Render("\n\n  r retry • q quit"))
+		return renderBoxWidth(b.String(), m.width)
+	}
+
+	rows := m.rows()
+	start, end := visibleRange(len(rows), m.cursor, m.splitListHeight())
+	if start > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more", start)))
+		b.WriteString("\n")
+	}
+	for i := start; i < end; i++ {
+		r := rows[i]
+		prefix, prefixView := "    ", "    "
+		style := normalStyle
+		if i == m.cursor {
+			prefix, prefixView = "  > ", cursorStyle.Render("  > ")
+			style = selectedStyle
+		}
+
+		if r.unit < 0 {
+			label := fmt.Sprintf("%d. ", r.group+1)
+			subject, _, _ := strings.Cut(m.groups[r.group].Message, "\n")
+			if subject == "" {
+				b.WriteString(prefixView + style.Render(label) + errorStyle.Render("(no message)"))
+			} else {
+				b.WriteString(renderWrappedLine(prefix+label, prefixView+style.Render(label), subject, highlightStyle, contentWidth))
+			}
+			b.WriteString("\n")
+			continue
+		}
+
+		u := m.units[r.unit]
+		line := truncateWidth(u.Path, contentWidth/2)
+		b.WriteString(prefixView + "   " + style.Render(line))
+		b.WriteString("  " + dimStyle.Render(truncateWidth(u.Label, contentWidth-len([]rune(line))-12)))
+		b.WriteString("\n")
+	}
+	if end < len(rows) {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("    ↓ %d more", len(rows)-end)))
+		b.WriteString("\n")
+	}
+
+	if m.notice != "" {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render(wrapText("✗ "+m.notice, contentWidth)))
+		b.WriteString("\n")
+	}
+	if m.confirming {
+		b.WriteString("\n")
+		b.WriteString(selectedStyle.Render(fmt.Sprintf("Create %d commits in this order? (y/n)", len(m.groups))))
+		b.WriteString(helpStyle.Render("\n  y/enter commit • n/esc back • q quit"))
+	} else {
+		b.WriteString(helpStyle.Render("\n  ↑/↓ move • ←/→ move hunk to previous/next commit • n new commit • K/J reorder • e edit message • r regroup • enter commit • q quit"))
+	}
+
+	return renderBoxWidth(b.String(), m.width)
+}
+
+// Groups returns the final commits in order, or nil when the user quit
+// without applying.
+func (m SplitModel) Groups() []llm.SplitGroup {
+	if !m.applied {
+		return nil
+	}
+	return m.groups
+}
+
+// RunSplit shows the grouping editor and returns the commits to create, or
+// nil when the user cancelled.
+func RunSplit(cfg *config.Config, repo *git.Repo, units []SplitUnit) ([]llm.SplitGroup, error) {
+	if err := resolveCredentials(cfg); err != nil {
+		return nil, err
+	}
+	p := tea.NewProgram(NewSplitModel(cfg, repo, units), tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	return final.(SplitModel).Groups(), nil
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+	"github.com/lieyanc/fire-commit/internal/llm"
+)
+
+func newSplitTestModel() SplitModel {
+	m := NewSplitModel(config.DefaultConfig(), git.Open(""), []SplitUnit{
+		{Path: "api.go", Label: "@@ -1 +1 @@"},
+		{Path: "api_test.go", Label: "@@ -1 +1 @@"},
+		{Path: "README.md", Label: "@@ -1 +1 @@"},
+	})
+	return updateSplit(m, splitPlanMsg{generation: 1, groups: []llm.SplitGroup{
+		{Message: "feat: add api", Hunks: []int{0, 1}},
+	}})
+}
+
+func updateSplit(m SplitModel, msg tea.Msg) SplitModel {
+	next, _ := m.Update(msg)
+	return next.(SplitModel)
+}
+
+func splitKey(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestSplitCollectsUnassignedUnits(t *testing.T) {
+	t.Parallel()
+
+	m := newSplitTestModel()
+	if len(m.groups) != 2 || m.groups[1].Message != "" || len(m.groups[1].Hunks) != 1 || m.groups[1].Hunks[0] != 2 {
+		t.Fatalf("groups got %+v", m.groups)
+	}
+
+	// Enter refuses while a commit has no message.
+	m = updateSplit(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.confirming || m.notice == "" {
+		t.Fatalf("enter should point at the commit without a message")
+	}
+	if r := m.rows()[m.cursor]; r.group != 1 || r.unit != -1 {
+		t.Fatalf("cursor should move to the commit without a message, got %+v", r)
+	}
+}
+
+func TestSplitMovesUnitsBetweenCommits(t *testing.T) {
+	t.Parallel()
+
+	m := newSplitTestModel()
+	// Rows: [group 0, api.go, api_test.go, group 1, README.md].
+	m = updateSplit(m, splitKey("j"))
+	m = updateSplit(m, splitKey("j"))
+	m = updateSplit(m, splitKey("n"))
+	if len(m.groups) != 3 || m.groups[2].Hunks[0] != 1 {
+		t.Fatalf("n should move the hunk to a new commit, got %+v", m.groups)
+	}
+	if r := m.rows()[m.cursor]; r.unit != 1 {
+		t.Fatalf("cursor should follow the moved hunk, got %+v", r)
+	}
+
+	// Moving README.md back into the first commit empties and drops group 1.
+	m.cursor = m.rowIndex(0, 2)
+	m = updateSplit(m, tea.KeyMsg{Type: tea.KeyLeft})
+	if len(m.groups) != 2 || len(m.groups[0].Hunks) != 2 || m.groups[0].Hunks[1] != 2 {
+		t.Fatalf("groups got %+v", m.groups)
+	}
+}
+
+func TestSplitReorderEditAndApply(t *testing.T) {
+	t.Parallel()
+
+	m := newSplitTestModel()
+	m.cursor = m.rowIndex(1, -1)
+	m = updateSplit(m, splitKey("K"))
+	if len(m.groups[0].Hunks) != 1 || m.groups[0].Hunks[0] != 2 {
+		t.Fatalf("K should move the commit up, got %+v", m.groups)
+	}
+
+	m = updateSplit(m, splitKey("e"))
+	m.editArea.SetValue("docs: describe api")
+	m = updateSplit(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updateSplit(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.confirming {
+		t.Fatalf("enter should ask for confirmation")
+	}
+	m = updateSplit(m, splitKey("y"))
+
+	got := m.Groups()
+	if len(got) != 2 || got[0].Message != "docs: describe api" || got[1].Message != "feat: add api" {
+		t.Fatalf("groups got %+v", got)
+	}
+}
+
+func TestSplitListingShortensUnits(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("+x\n", 100)
+	listing := splitListing([]SplitUnit{{Path: "a.go", Patch: long}}, 10)
+	if want := "... (81 more lines)"; !strings.Contains(listing, want) {
+		t.Fatalf("listing should be cut to %d lines:\n%s", minSplitUnitLines, listing)
+	}
+}
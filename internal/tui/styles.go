@@ -52,4 +52,32 @@ var (
 
 	highlightStyle = lipgloss.NewStyle().
 			Foreground(colorHighlight)
+
+	chipStyle = lipgloss.NewStyle().
+			Foreground(colorText).
+			Background(colorDeep).
+			Padding(0, 1)
+
+	addedLineStyle = lipgloss.NewStyle().
+			Foreground(colorSuccess)
+
+	removedLineStyle = lipgloss.NewStyle().
+				Foreground(colorError)
+
+	hunkHeaderStyle = lipgloss.NewStyle().
+			Foreground(colorAccent)
+
+	diffFileStyle = lipgloss.NewStyle().
+			Foreground(colorHighlight).
+			Bold(true)
+
+	diffPanelStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder(), false, true, false, false).
+			BorderForeground(colorDim)
+
+	breakingChipStyle = lipgloss.NewStyle().
+				Foreground(colorText).
+				Background(colorError).
+				Bold(true).
+				Padding(0, 1)
 )
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+// Choices offered when the upstream has new commits.
+const (
+	syncChoiceRebase = iota
+	syncChoiceMerge
+	syncChoiceSkip
+
+	syncChoiceCount = 3
+)
+
+// fetchDoneMsg reports how far the branch is behind its upstream after
+// fetching.
+type fetchDoneMsg struct {
+	behind int
+	err    error
+}
+
+// syncDoneMsg reports the result of the rebase or merge.
+type syncDoneMsg struct{ err error }
+
+// needsSync reports whether the upstream is fetched before pushing. New
+// branches have nothing to catch up with, and an amend of a pushed commit
+// replaces the remote one with --force-with-lease instead.
+func (m Model) needsSync() bool {
+	return m.wantPush &&
+		m.pushTarget.Upstream != "" &&
+		m.cfg.Push.Sync() != config.SyncOff &&
+		!(m.amend && m.headPushed)
+}
+
+// pushPending reports whether the push still has to run after the commit.
+func (m Model) pushPending() bool {
+	return m.wantPush && m.syncErr == nil && !m.pushSkipped
+}
+
+// continueAfterCommit starts the steps after a successful commit: syncing
+// with the upstream, tagging and pushing.
+func (m Model) continueAfterCommit() (Model, tea.Cmd) {
+	if m.needsSync() {
+		m.fetching = true
+		return m, tea.Batch(m.spinner.Tick, m.doFetch())
+	}
+	return m.continueAfterSync()
+}
+
+// continueAfterSync tags and pushes, or finishes.
+func (m Model) continueAfterSync() (Model, tea.Cmd) {
+	switch {
+	case m.versionTag != "":
+		return m, tea.Batch(m.spinner.Tick, m.doTag())
+	case m.pushPending():
+		return m, tea.Batch(m.spinner.Tick, m.doGitPush())
+	}
+	m.phase = PhaseDone
+	return m, nil
+}
+
+func (m Model) doFetch() tea.Cmd {
+	repo := m.repo
+	return func() tea.Msg {
+		if err := repo.FetchUpstream(); err != nil {
+			return fetchDoneMsg{err: err}
+		}
+		_, behind, err := repo.UpstreamDivergence()
+		return fetchDoneMsg{behind: behind, err: err}
+	}
+}
+
+func (m Model) doSync(choice int) tea.Cmd {
+	upstream := m.pushTarget.Upstream
+	repo := m.repo
+	return func() tea.Msg {
+		if choice == syncChoiceMerge {
+			return syncDoneMsg{err: repo.MergeUpstream(upstream)}
+		}
+		return syncDoneMsg{err: repo.RebaseOntoUpstream(upstream)}
+	}
+}
+
+// defaultSyncChoice is the option highlighted when the upstream has new
+// commits.
+func (m Model) defaultSyncChoice() int {
+	if m.cfg.Push.Sync() == config.SyncMerge {
+		return syncChoiceMerge
+	}
+	return syncChoiceRebase
+}
+
+func (m Model) updateSync(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case fetchDoneMsg:
+		m.fetching = false
+		switch {
+		case msg.err != nil:
+			m.syncErr = msg.err
+			return m.continueAfterSync()
+		case msg.behind == 0:
+			return m.continueAfterSync()
+		}
+		m.behind = msg.behind
+		m.syncPrompt = true
+		m.syncCursor = m.defaultSyncChoice()
+		return m, nil
+
+	case syncDoneMsg:
+		m.syncing = false
+		if msg.err != nil {
+			m.syncErr = msg.err
+		} else {
+			m.synced = true
+		}
+		return m.continueAfterSync()
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Tab), key.Matches(msg, keys.Down):
+			m.syncCursor = (m.syncCursor + 1) % syncChoiceCount
+		case key.Matches(msg, keys.Up):
+			m.syncCursor = (m.syncCursor + syncChoiceCount - 1) % syncChoiceCount
+		case key.Matches(msg, keys.Enter):
+			m.syncPrompt = false
+			if m.syncCursor == syncChoiceSkip {
+				m.pushSkipped = true
+				return m.continueAfterSync()
+			}
+			m.syncing = true
+			m.syncChoice = m.syncCursor
+			return m, tea.Batch(m.spinner.Tick, m.doSync(m.syncCursor))
+		case key.Matches(msg, keys.Escape):
+			m.syncPrompt = false
+			m.pushSkipped = true
+			return m.continueAfterSync()
+		}
+	}
+	return m, nil
+}
+
+// syncVerb names the integration for the result views.
+func (m Model) syncVerb() string {
+	if m.syncChoice == syncChoiceMerge {
+		return "Merged " + m.pushTarget.Upstream
+	}
+	return "Rebased onto " + m.pushTarget.Upstream
+}
+
+// viewSync renders the sync step. It returns true while the step is still
+// running or waiting for the user, so later steps are not shown yet.
+func (m Model) viewSync(b *strings.Builder, width int) bool {
+	upstream := m.pushTarget.Upstream
+	switch {
+	case m.fetching:
+		b.WriteString(m.spinner.View() + " Fetching " + upstream + "...")
+		return true
+	case m.syncPrompt:
+		b.WriteString("\n")
+		b.WriteString(wrapText(fmt.Sprintf("%s has %d new commit(s). Bring them in before pushing?", upstream, m.behind), width))
+		b.WriteString("\n\n")
+		options := []string{"Rebase onto " + upstream, "Merge " + upstream, "Don't push"}
+		for i, opt := range options {
+			if i == m.syncCursor {
+				b.WriteString(renderWrappedLine("  > ", cursorStyle.Render("  > "), opt, selectedStyle, width))
+			} else {
+				b.WriteString(renderWrappedLine("    ", "    ", opt, normalStyle, width))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(helpStyle.Render("\n  ↑/↓/tab select • enter confirm • esc don't push"))
+		return true
+	case m.syncing:
+		verb := "Rebasing onto "
+		if m.syncChoice == syncChoiceMerge {
+			verb = "Merging "
+		}
+		b.WriteString(m.spinner.View() + " " + verb + upstream + "...")
+		return true
+	}
+	m.viewSyncResult(b, width)
+	return false
+}
+
+// viewSyncResult shows the outcome of the sync step, if any.
+func (m Model) viewSyncResult(b *strings.Builder, width int) {
+	switch {
+	case m.synced:
+		b.WriteString(successStyle.Render("✓ " + m.syncVerb()))
+		b.WriteString("\n")
+	case m.syncErr != nil:
+		b.WriteString(errorStyle.Render(wrapText("✗ Not pushed: "+m.syncErr.Error(), width)))
+		b.WriteString("\n")
+		var conflict *git.ConflictError
+		if errors.As(m.syncErr, &conflict) {
+			hint := "Run git pull --rebase to resolve the conflicts, then push."
+			if conflict.Op == "merge" {
+				hint = "Run git pull --no-rebase to resolve the conflicts, then push."
+			}
+			b.WriteString(dimStyle.Render(wrapText(hint, width)))
+			b.WriteString("\n")
+		}
+	case m.pushSkipped:
+		b.WriteString(dimStyle.Render(wrapText(fmt.Sprintf("– Not pushed: %s has %d new commit(s)", m.pushTarget.Upstream, m.behind), width)))
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+func newSyncTestModel(cfg *config.Config) Model {
+	m := newConfirmTestModel(cfg)
+	m.phase = PhaseCommitting
+	m.committed = true
+	m.wantPush = true
+	m.pushTarget = git.PushTarget{Branch: "main", Upstream: "origin/main", UpstreamRemote: "origin", Remotes: []string{"origin"}}
+	return m
+}
+
+func TestSyncPromptsWhenBehindAndPreselectsConfiguredMode(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultConfig()
+	cfg.Push.SyncMode = config.SyncMerge
+	m := newSyncTestModel(cfg)
+	if !m.needsSync() {
+		t.Fatalf("a branch with upstream should be synced before pushing")
+	}
+
+	next, _ := m.updateCommitting(fetchDoneMsg{behind: 2})
+	m = next.(Model)
+	if !m.syncPrompt || m.syncCursor != syncChoiceMerge {
+		t.Fatalf("sync prompt got prompt=%v cursor=%d", m.syncPrompt, m.syncCursor)
+	}
+	if !strings.Contains(m.viewCommitting(), "origin/main has 2 new commit(s)") {
+		t.Fatalf("committing view should report the new commits:\n%s", m.viewCommitting())
+	}
+
+	next, cmd := m.updateCommitting(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if !m.syncing || m.syncChoice != syncChoiceMerge || cmd == nil {
+		t.Fatalf("enter should start the merge, got syncing=%v choice=%d", m.syncing, m.syncChoice)
+	}
+}
+
+func TestSyncConflictSkipsPush(t *testing.T) {
+	t.Parallel()
+
+	m := newSyncTestModel(config.DefaultConfig())
+	m.syncing = true
+	conflict := &git.ConflictError{Op: "rebase", Upstream: "origin/main", Files: []string{"main.go"}}
+
+	next, _ := m.updateCommitting(syncDoneMsg{err: conflict})
+	m = next.(Model)
+	if m.phase != PhaseDone || m.pushPending() {
+		t.Fatalf("a conflict should finish without pushing, got phase=%v", m.phase)
+	}
+	view := m.viewDone()
+	if !strings.Contains(view, "main.go") || !strings.Contains(view, "git pull --rebase") {
+		t.Fatalf("done view should list the conflicts and a hint:\n%s", view)
+	}
+}
+
+func TestSyncSkippedWhenUpToDateOrOff(t *testing.T) {
+	t.Parallel()
+
+	m := newSyncTestModel(config.DefaultConfig())
+	next, cmd := m.updateCommitting(fetchDoneMsg{behind: 0})
+	m = next.(Model)
+	if m.syncPrompt || cmd == nil || !m.pushPending() {
+		t.Fatalf("an up-to-date branch should be pushed right away")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Push.SyncMode = config.SyncOff
+	if newSyncTestModel(cfg).needsSync() {
+		t.Fatalf("sync: off should push without fetching")
+	}
+}
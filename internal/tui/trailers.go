@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+// trailerChoice is one saved trailer offered on the confirm screen.
+type trailerChoice struct {
+	key   string
+	value string
+	on    bool
+}
+
+func (t trailerChoice) String() string {
+	return t.key + ": " + t.value
+}
+
+// CommitOptions builds the commit options configured in cfg. Saved
+// co-authors and reviewers are not included; they are picked per commit.
+func CommitOptions(cfg *config.Config) git.CommitOptions {
+	return git.CommitOptions{
+		Sign:     cfg.Commit.Sign,
+		Signoff:  cfg.Commit.Signoff,
+		NoVerify: cfg.Commit.NoVerify,
+		Trailers: slices.Clone(cfg.Commit.Trailers),
+	}
+}
+
+// trailerChoices lists the saved co-authors and reviewers.
+func trailerChoices(cfg *config.Config) []trailerChoice {
+	var choices []trailerChoice
+	for _, v := range cfg.Commit.CoAuthors {
+		choices = append(choices, trailerChoice{key: "Co-authored-by", value: v})
+	}
+	for _, v := range cfg.Commit.Reviewers {
+		choices = append(choices, trailerChoice{key: "Reviewed-by", value: v})
+	}
+	return choices
+}
+
+// selectedTrailers returns the configured trailers followed by the picked
+// ones.
+func (m Model) selectedTrailers() []string {
+	trailers := slices.Clone(m.commitOpts.Trailers)
+	for _, t := range m.trailers {
+		if t.on {
+			trailers = append(trailers, t.String())
+		}
+	}
+	return trailers
+}
+
+func (m Model) updateTrailerPicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.trailerCursor > 0 {
+				m.trailerCursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.trailerCursor < len(m.trailers)-1 {
+				m.trailerCursor++
+			}
+		case key.Matches(msg, keys.Toggle):
+			m.trailers[m.trailerCursor].on = !m.trailers[m.trailerCursor].on
+		case key.Matches(msg, keys.Enter), key.Matches(msg, keys.Escape), key.Matches(msg, keys.Trailers):
+			m.pickingTrailers = false
+		}
+	}
+	return m, nil
+}
+
+func (m Model) viewTrailerPicker() string {
+	var b strings.Builder
+	contentWidth := m.contentWidth()
+
+	b.WriteString("Trailers:\n")
+	for i, t := range m.trailers {
+		box := "[ ]"
+		if t.on {
+			box = "[x]"
+		}
+		line := box + " " + t.String()
+		if i == m.trailerCursor {
+			b.WriteString(renderWrappedLine("  > ", cursorStyle.Render("  > "), line, selectedStyle, contentWidth))
+		} else {
+			b.WriteString(renderWrappedLine("    ", "    ", line, normalStyle, contentWidth))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("\n  ↑/↓ move • space toggle • enter/esc done"))
+	return b.String()
+}
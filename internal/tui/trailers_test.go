@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lieyanc/fire-commit/internal/config"
+	"github.com/lieyanc/fire-commit/internal/git"
+)
+
+func newConfirmTestModel(cfg *config.Config) Model {
+	m := NewModel(cfg, git.Open(""), "diff", "stat")
+	m.messages = []string{"feat: add api"}
+	m.phase = PhaseConfirm
+	return m
+}
+
+func pressConfirm(m Model, keys ...string) Model {
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(k)}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	return m
+}
+
+func TestConfirmTogglesCommitOptions(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultConfig()
+	cfg.Commit.Sign = true
+	m := newConfirmTestModel(cfg)
+	if !m.commitOpts.Sign {
+		t.Fatalf("sign should start from the config")
+	}
+
+	m = pressConfirm(m, "s", "o", "n")
+	if m.commitOpts.Sign || !m.commitOpts.Signoff || !m.commitOpts.NoVerify {
+		t.Fatalf("options got %+v", m.commitOpts)
+	}
+	if !cfg.Commit.Sign {
+		t.Fatalf("toggles must not change the config")
+	}
+}
+
+func TestConfirmPicksSavedTrailers(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultConfig()
+	cfg.Commit.Trailers = []string{"Refs: PROJ-1"}
+	cfg.Commit.CoAuthors = []string{"Jane Doe <jane@example.com>", "Sam <sam@example.com>"}
+	cfg.Commit.Reviewers = []string{"Alex <alex@example.com>"}
+	m := newConfirmTestModel(cfg)
+
+	// Pick the second co-author and the reviewer.
+	m = pressConfirm(m, "t", "j", " ", "j", " ", "enter")
+	if m.pickingTrailers || m.phase != PhaseConfirm {
+		t.Fatalf("enter should close the picker, picking=%v phase=%v", m.pickingTrailers, m.phase)
+	}
+	want := []string{
+		"Refs: PROJ-1",
+		"Co-authored-by: Sam <sam@example.com>",
+		"Reviewed-by: Alex <alex@example.com>",
+	}
+	if got := m.selectedTrailers(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("trailers got %q want %q", got, want)
+	}
+	if !strings.Contains(m.viewConfirm(), "Co-authored-by: Sam") {
+		t.Fatalf("confirm view should list the picked trailers")
+	}
+}
+
+func TestDoneShowsCommitOutput(t *testing.T) {
+	t.Parallel()
+
+	m := newConfirmTestModel(config.DefaultConfig())
+	next, _ := m.updateCommitting(commitDoneMsg{err: &git.CommitError{Output: "lint failed\nmain.go:3: unused variable"}})
+	m = next.(Model)
+
+	view := m.viewDone()
+	if m.phase != PhaseDone || !strings.Contains(view, "main.go:3: unused variable") {
+		t.Fatalf("done view should show the hook output:\n%s", view)
+	}
+	if !strings.Contains(view, "no-verify") {
+		t.Fatalf("done view should mention no-verify:\n%s", view)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/lieyanc/fire-commit/internal/debuglog"
 )
 
 const (
@@ -189,7 +191,9 @@ func fetchReleaseByURL(ctx context.Context, url, etag string) (*Release, string,
 		req.Header.Set("If-None-Match", etag)
 	}
 
+	start := time.Now()
 	resp, err := http.DefaultClient.Do(req)
+	logUpdateRequest(url, etag != "", resp, start, err)
 	if err != nil {
 		return nil, "", false, 0, err
 	}
@@ -213,6 +217,24 @@ func fetchReleaseByURL(ctx context.Context, url, etag string) (*Release, string,
 	return &release, responseETag, false, http.StatusOK, nil
 }
 
+// logUpdateRequest records one GitHub release API call in the debug log.
+func logUpdateRequest(url string, conditional bool, resp *http.Response, start time.Time, err error) {
+	if !debuglog.Enabled() {
+		return
+	}
+	fields := map[string]any{
+		"url":         url,
+		"conditional": conditional,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	} else {
+		fields["status"] = resp.StatusCode
+	}
+	debuglog.Log("update.check", fields)
+}
+
 func parseLatestChannelETags(raw string) latestChannelETags {
 	var out latestChannelETags
 	if raw == "" || !strings.HasPrefix(raw, "{") {